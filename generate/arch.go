@@ -0,0 +1,41 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/flywingedai/components/generate/componentgraph"
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/spf13/cobra"
+)
+
+func newArchCmd() *cobra.Command {
+
+	archCommand := &cobra.Command{}
+
+	archCommand.Use = "arch $DIRECTORY"
+	archCommand.Short = "Check the component dependency graph against the architecture rules in the components config"
+	archCommand.Example = "components arch ./ --config .components.yml"
+	archCommand.Args = cobra.ExactArgs(1)
+
+	archCommand.Run = func(cmd *cobra.Command, args []string) {
+		componentsConfig := loadConfig(cmd)
+
+		p := componentparser.New()
+		p.Args.Directory = args[0]
+		p.Parse()
+
+		violations := componentgraph.New(p.Structs).Check(componentsConfig.Arch)
+		if len(violations) == 0 {
+			return
+		}
+
+		for _, violation := range violations {
+			fmt.Fprintln(os.Stderr, violation.String())
+		}
+		fmt.Fprintf(os.Stderr, "found %d architecture violations\n", len(violations))
+		os.Exit(1)
+	}
+
+	return archCommand
+}
@@ -0,0 +1,229 @@
+package componentgraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flywingedai/components/generate/config"
+)
+
+/*
+A broken architecture rule. Points at the field of the component struct which
+caused the violation.
+*/
+type Violation struct {
+	Component string // ID of the component with the offending field
+	Field     string // Name of the offending field
+	File      string
+	Line      int
+	Column    int
+	Message   string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", v.File, v.Line, v.Column, v.Message)
+}
+
+/*
+Check the graph against the architecture rules. Violations are returned sorted
+by their position.
+*/
+func (g *Graph) Check(arch config.ArchConfig) []Violation {
+	violations := []Violation{}
+
+	for _, node := range g.Nodes {
+		for _, rule := range arch.Rules {
+			if !MatchPackage(rule.Package, node.PackagePath, node.Package) {
+				continue
+			}
+			violations = append(violations, node.checkRule(rule)...)
+		}
+	}
+
+	if arch.NoCycles {
+		violations = append(violations, g.checkCycles()...)
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		return violations[i].Line < violations[j].Line
+	})
+	return violations
+}
+
+// Check a single rule against all the dependencies of a node
+func (node *Node) checkRule(rule config.ArchRule) []Violation {
+	violations := []Violation{}
+
+	matchAny := func(patterns []string, dependency *Dependency) bool {
+		for _, pattern := range patterns {
+			if MatchPackage(pattern, dependency.PackagePath, dependency.Package) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, dependency := range node.Dependencies {
+
+		// Denied packages apply to every field, component or not
+		if matchAny(rule.Deny, dependency) {
+			violations = append(violations, node.violation(dependency, fmt.Sprintf(
+				"package %s may not depend on %s (%s)", node.Package, dependency.PackagePath, rule.Package,
+			)))
+		}
+
+		// Allowed packages only apply to dependencies on other components
+		isExternalComponent := dependency.Component != "" && dependency.PackagePath != node.PackagePath
+		if len(rule.Allow) > 0 && isExternalComponent && !matchAny(rule.Allow, dependency) {
+			violations = append(violations, node.violation(dependency, fmt.Sprintf(
+				"package %s may only depend on components in %s, not %s (%s)", node.Package, strings.Join(rule.Allow, ", "), dependency.PackagePath, rule.Package,
+			)))
+		}
+
+		if len(rule.MockScope) > 0 && dependency.Mock && !matchAny(rule.MockScope, dependency) {
+			violations = append(violations, node.violation(dependency, fmt.Sprintf(
+				"mocked dependencies of package %s must be in %s, not %s (%s)", node.Package, strings.Join(rule.MockScope, ", "), dependency.PackagePath, rule.Package,
+			)))
+		}
+	}
+
+	return violations
+}
+
+/*
+Find every dependency cycle between components. Each strongly connected
+component of the graph is reported once, at the field which leaves the first
+component of the cycle.
+*/
+func (g *Graph) checkCycles() []Violation {
+	violations := []Violation{}
+
+	for _, cycle := range g.cycles() {
+		node := g.Node(cycle[0].From)
+
+		path := []string{}
+		for _, edge := range cycle {
+			path = append(path, g.Node(edge.From).Package+"."+g.Node(edge.From).Name+"."+edge.Field)
+		}
+		path = append(path, node.Package+"."+node.Name)
+
+		for _, dependency := range node.Dependencies {
+			if dependency.Field == cycle[0].Field {
+				violations = append(violations, node.violation(dependency, "dependency cycle: "+strings.Join(path, " -> ")))
+			}
+		}
+	}
+
+	return violations
+}
+
+/*
+Returns one cycle for each group of components which depend on each other. The
+groups are found with Tarjan's algorithm and a cycle is then traced through
+each group starting at its first node.
+*/
+func (g *Graph) cycles() [][]*Edge {
+	index := 0
+	indices := map[string]int{}
+	lowLinks := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	groups := [][]string{}
+
+	var connect func(id string)
+	connect = func(id string) {
+		indices[id] = index
+		lowLinks[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, edge := range g.EdgesFrom(id) {
+			if _, visited := indices[edge.To]; !visited {
+				connect(edge.To)
+				lowLinks[id] = min(lowLinks[id], lowLinks[edge.To])
+			} else if onStack[edge.To] {
+				lowLinks[id] = min(lowLinks[id], indices[edge.To])
+			}
+		}
+
+		if lowLinks[id] == indices[id] {
+			group := []string{}
+			for {
+				last := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[last] = false
+				group = append(group, last)
+				if last == id {
+					break
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+
+	for _, node := range g.Nodes {
+		if _, visited := indices[node.ID]; !visited {
+			connect(node.ID)
+		}
+	}
+
+	cycles := [][]*Edge{}
+	for _, group := range groups {
+		members := map[string]bool{}
+		for _, id := range group {
+			members[id] = true
+		}
+		sort.Strings(group)
+
+		// Trace a path through the group back to the first node
+		cycle := g.traceCycle(group[0], members)
+		if cycle != nil {
+			cycles = append(cycles, cycle)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0].From < cycles[j][0].From
+	})
+	return cycles
+}
+
+// Depth first search for a path from start back to itself within the members
+func (g *Graph) traceCycle(start string, members map[string]bool) []*Edge {
+	visited := map[string]bool{}
+
+	var search func(id string) []*Edge
+	search = func(id string) []*Edge {
+		visited[id] = true
+		for _, edge := range g.EdgesFrom(id) {
+			if edge.To == start {
+				return []*Edge{edge}
+			}
+			if members[edge.To] && !visited[edge.To] {
+				if path := search(edge.To); path != nil {
+					return append([]*Edge{edge}, path...)
+				}
+			}
+		}
+		return nil
+	}
+
+	return search(start)
+}
+
+// Create a violation for a field of this node
+func (node *Node) violation(dependency *Dependency, message string) Violation {
+	return Violation{
+		Component: node.ID,
+		Field:     dependency.Field,
+		File:      node.File,
+		Line:      dependency.Line,
+		Column:    dependency.Column,
+		Message:   node.Package + "." + node.Name + "." + dependency.Field + ": " + message,
+	}
+}
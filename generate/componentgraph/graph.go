@@ -0,0 +1,268 @@
+package componentgraph
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/flywingedai/components/generate/componentparser"
+)
+
+/*
+The component dependency graph. Each node is a component found by the parser
+and each edge is a field of one component which holds another component.
+*/
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+// A single component in the graph
+type Node struct {
+	ID            string `json:"id"`            // Import path of the package + "." + the struct name
+	Name          string `json:"name"`          // Name of the component struct
+	Package       string `json:"package"`       // Name of the package the component lives in
+	PackagePath   string `json:"packagePath"`   // Import path of the package the component lives in
+	Interface     string `json:"interface"`     // Name of the generated interface
+	InterfacePath string `json:"interfacePath"` // Import path of the package of the generated interface
+	File          string `json:"file"`          // File the component struct was found in
+
+	// Every field with a named type, whether or not it is another component
+	Dependencies []*Dependency `json:"dependencies"`
+
+	Struct *componentparser.StructData `json:"-"`
+}
+
+/*
+A field of a component with a named type. Component is the ID of the node
+providing the dependency, and is empty when the type is not a component.
+*/
+type Dependency struct {
+	Field       string `json:"field"`
+	Type        string `json:"type"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	Package     string `json:"package"`
+	PackagePath string `json:"packagePath"`
+	TypeName    string `json:"typeName"`
+	Mock        bool   `json:"mock"`
+	Component   string `json:"component,omitempty"`
+
+	FieldData componentparser.Field `json:"-"`
+}
+
+// A dependency between two components
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Field string `json:"field"`
+	Mock  bool   `json:"mock"`
+}
+
+/*
+Build the graph from the parsed structs. A field depends on a component when
+its type is the generated interface of that component, or the component struct
+itself.
+*/
+func New(structs map[string]*componentparser.StructData) *Graph {
+	graph := &Graph{Nodes: []*Node{}, Edges: []*Edge{}}
+
+	// Index the components by each of the types that refer to them
+	providers := map[string]string{}
+	for _, structData := range structs {
+		node := &Node{
+			ID:            structData.PackagePath + "." + structData.Name,
+			Name:          structData.Name,
+			Package:       structData.PackageName,
+			PackagePath:   structData.PackagePath,
+			Interface:     structData.Options.InterfaceName,
+			InterfacePath: structData.Options.InterfacePath,
+			File:          structData.StructFile,
+			Dependencies:  []*Dependency{},
+			Struct:        structData,
+		}
+		graph.Nodes = append(graph.Nodes, node)
+
+		providers[node.ID] = node.ID
+		providers[node.InterfacePath+"."+node.Interface] = node.ID
+	}
+
+	/*
+		Sort the nodes so all output is deterministic. The edges are created in
+		node order and then field order so they don't need sorting.
+	*/
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+
+	for _, node := range graph.Nodes {
+		for _, field := range node.Struct.Fields {
+			importPath, typeName, ok := node.Struct.ResolveType(field.Type)
+			if !ok {
+				continue
+			}
+
+			// Local types have the name of the package the struct is in
+			packageName := node.Package
+			if importPath != node.PackagePath {
+				packageName = node.Struct.ImportNames[importPath]
+			}
+
+			dependency := &Dependency{
+				Field:       field.Name,
+				Type:        field.Type,
+				Line:        field.Line,
+				Column:      field.Column,
+				Package:     packageName,
+				PackagePath: importPath,
+				TypeName:    typeName,
+				Mock:        field.MockPkg != "",
+				Component:   providers[importPath+"."+typeName],
+				FieldData:   field,
+			}
+			node.Dependencies = append(node.Dependencies, dependency)
+
+			if dependency.Component != "" {
+				graph.Edges = append(graph.Edges, &Edge{
+					From:  node.ID,
+					To:    dependency.Component,
+					Field: field.Name,
+					Mock:  dependency.Mock,
+				})
+			}
+		}
+	}
+
+	return graph
+}
+
+///////////
+// QUERY //
+///////////
+
+// Fetch a node by its ID. Returns nil if it does not exist.
+func (g *Graph) Node(id string) *Node {
+	for _, node := range g.Nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+	return nil
+}
+
+/*
+Find all the nodes matching a name. The name may be the full ID, the package
+name and struct name joined by a ".", the struct name or the interface name.
+*/
+func (g *Graph) Find(name string) []*Node {
+	nodes := []*Node{}
+	for _, node := range g.Nodes {
+		if node.Matches(name) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Whether or not the name refers to this node. See Graph.Find
+func (node *Node) Matches(name string) bool {
+	return node.ID == name || node.Package+"."+node.Name == name || node.Name == name || node.Interface == name
+}
+
+// All the edges leaving the node with the given ID
+func (g *Graph) EdgesFrom(id string) []*Edge {
+	edges := []*Edge{}
+	for _, edge := range g.Edges {
+		if edge.From == id {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+/*
+Determine if a package pattern matches a package. Patterns containing a "/" are
+matched against the import path, with a trailing "/..." matching the path and
+all of its children. Any other pattern is matched against the package name.
+*/
+func MatchPackage(pattern, packagePath, packageName string) bool {
+	if !strings.Contains(pattern, "/") {
+		return pattern == packageName
+	}
+
+	if strings.HasSuffix(pattern, "/...") {
+		prefix := strings.TrimSuffix(pattern, "/...")
+		return packagePath == prefix || strings.HasPrefix(packagePath, prefix+"/")
+	}
+
+	matched, err := path.Match(pattern, packagePath)
+	return err == nil && matched
+}
+
+/////////////
+// FILTERS //
+/////////////
+
+// Keep only the components in packages matching one of the patterns
+func (g *Graph) FilterPackages(patterns []string) *Graph {
+	return g.subgraph(func(node *Node) bool {
+		for _, pattern := range patterns {
+			if MatchPackage(pattern, node.PackagePath, node.Package) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+/*
+Keep only the components reachable from the named roots. Panics if a root does
+not match exactly one component.
+*/
+func (g *Graph) FilterRoots(roots []string) *Graph {
+	reachable := map[string]bool{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		if reachable[id] {
+			return
+		}
+		reachable[id] = true
+		for _, edge := range g.EdgesFrom(id) {
+			visit(edge.To)
+		}
+	}
+
+	for _, root := range roots {
+		nodes := g.Find(root)
+		if len(nodes) != 1 {
+			panic("root component " + root + " must match exactly one component")
+		}
+		visit(nodes[0].ID)
+	}
+
+	return g.subgraph(func(node *Node) bool {
+		return reachable[node.ID]
+	})
+}
+
+// Create a new graph with only the nodes that pass the filter
+func (g *Graph) subgraph(keep func(node *Node) bool) *Graph {
+	graph := &Graph{Nodes: []*Node{}, Edges: []*Edge{}}
+
+	kept := map[string]bool{}
+	for _, node := range g.Nodes {
+		if keep(node) {
+			kept[node.ID] = true
+			graph.Nodes = append(graph.Nodes, node)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		if kept[edge.From] && kept[edge.To] {
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+
+	return graph
+}
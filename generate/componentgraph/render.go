@@ -0,0 +1,96 @@
+package componentgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+/*
+Render the graph in the DOT format. Components are grouped into a cluster per
+package and mocked dependencies are drawn with dashed edges.
+*/
+func (g *Graph) DOT() string {
+	dot := "digraph components {\n"
+	dot += "\trankdir=LR;\n"
+	dot += "\tnode [shape=box];\n"
+
+	// Group the nodes by package while keeping the sorted order
+	packages := []string{}
+	packageNodes := map[string][]*Node{}
+	for _, node := range g.Nodes {
+		if _, ok := packageNodes[node.PackagePath]; !ok {
+			packages = append(packages, node.PackagePath)
+		}
+		packageNodes[node.PackagePath] = append(packageNodes[node.PackagePath], node)
+	}
+
+	for i, packagePath := range packages {
+		dot += fmt.Sprintf("\n\tsubgraph cluster_%d {\n", i)
+		dot += fmt.Sprintf("\t\tlabel=%q;\n", packagePath)
+		for _, node := range packageNodes[packagePath] {
+			dot += fmt.Sprintf("\t\t%q [label=%q];\n", node.ID, node.Package+"."+node.Name)
+		}
+		dot += "\t}\n"
+	}
+
+	if len(g.Edges) > 0 {
+		dot += "\n"
+	}
+	for _, edge := range g.Edges {
+		style := ""
+		if edge.Mock {
+			style = ", style=dashed"
+		}
+		dot += fmt.Sprintf("\t%q -> %q [label=%q%s];\n", edge.From, edge.To, edge.Field, style)
+	}
+
+	dot += "}\n"
+	return dot
+}
+
+/*
+Render the graph as a Mermaid flowchart. Mermaid ids can't contain most of the
+characters in an import path, so each node is given a short id instead.
+*/
+func (g *Graph) Mermaid() string {
+	mermaid := "graph LR\n"
+
+	ids := map[string]string{}
+	for i, node := range g.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		mermaid += fmt.Sprintf("\t%s[\"%s.%s\"]\n", ids[node.ID], node.Package, node.Name)
+	}
+
+	for _, edge := range g.Edges {
+		arrow := "-->"
+		if edge.Mock {
+			arrow = "-.->"
+		}
+		mermaid += fmt.Sprintf("\t%s %s|%s| %s\n", ids[edge.From], arrow, edge.Field, ids[edge.To])
+	}
+
+	return mermaid
+}
+
+// Render the graph as indented JSON
+func (g *Graph) JSON() string {
+	data, err := json.MarshalIndent(g, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+	return string(data) + "\n"
+}
+
+// Render the graph in any of the supported formats: dot, mermaid or json
+func (g *Graph) Render(format string) string {
+	switch strings.ToLower(format) {
+	case "dot":
+		return g.DOT()
+	case "mermaid":
+		return g.Mermaid()
+	case "json":
+		return g.JSON()
+	}
+	panic("invalid graph format " + format + ". Must be one of dot, mermaid or json")
+}
@@ -0,0 +1,350 @@
+package componentmigrate
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/flywingedai/components/generate/helpers"
+	"golang.org/x/mod/modfile"
+)
+
+/*
+Option names which were renamed since a released version, keyed by the old name
+and commented with the version which used it. Every option of v1.2.0, the last
+release with the legacy layout, still has the same name, so there are none yet.
+*/
+var renamedOptions = map[string]string{}
+
+/*
+Values of the generate option which were renamed since a released version, keyed
+by the old value. v1.2.0 already used generate::components, so there are none
+yet.
+*/
+var renamedGenerate = map[string]string{}
+
+/*
+The folder mockery writes its mocks to by default. Mocks referenced through it
+are moved to the "$package_mocks" folders the generator uses.
+*/
+const mockeryFolder = "mocks"
+
+// A single change made while migrating
+type Change struct {
+	File    string
+	Line    int // 0 when the change applies to the whole file
+	Message string
+}
+
+func (c Change) String() string {
+	if c.Line == 0 {
+		return c.File + ": " + c.Message
+	}
+	return fmt.Sprintf("%s:%d: %s", c.File, c.Line, c.Message)
+}
+
+var (
+	optionPattern = regexp.MustCompile(`(\w+)::(\S*)`)
+	mockPkgTag    = regexp.MustCompile(`pkg:"` + mockeryFolder + `"`)
+)
+
+/*
+Rewrite everything in the directory, and all of its children, which was written
+for an older version of the generator. Every change is made in place and
+returned. The code itself is not regenerated here, which must happen afterwards
+to fill in the current layout.
+
+  - Code after the legacy disclaimer is removed, as it is replaced by regions
+  - Option names and values renamed since a released version are updated
+  - Mock fields referencing mockery's default "mocks" package are pointed at
+    the "$package_mocks" package, and the mockery generated mocks of those
+    interfaces are removed from the "mocks" folders. Mocks of interfaces which
+    no field was pointed away from are left alone.
+*/
+func Migrate(dir string) []Change {
+	m := &migrator{changes: []Change{}, repointed: map[string]bool{}}
+	mockeryFiles := []string{}
+
+	err := filepath.WalkDir(dir, func(fileName string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if fileName != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor" || d.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if filepath.Ext(fileName) != ".go" {
+			return nil
+		}
+
+		if filepath.Base(filepath.Dir(fileName)) == mockeryFolder && isMockery(fileName) {
+			mockeryFiles = append(mockeryFiles, fileName)
+			return nil
+		}
+
+		m.migrateFile(fileName)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	/*
+		Mockery mocks are only removed once the fields using them have been
+		pointed at the package the generator creates in their place. Mockery
+		wrote them into the "mocks" folder of the interface's package, and
+		names each mock after its interface.
+	*/
+	for _, fileName := range mockeryFiles {
+		mockFolder := filepath.Dir(fileName)
+		if !m.isRepointed(importPath(filepath.Dir(mockFolder)), mockTypes(fileName)) {
+			continue
+		}
+
+		err := os.Remove(fileName)
+		if err != nil {
+			panic(err)
+		}
+		m.changes = append(m.changes, Change{File: fileName, Message: "removed mock generated into the mockery default folder"})
+
+		// Don't leave the folder behind when it only held the mocks
+		entries, err := os.ReadDir(mockFolder)
+		if err == nil && len(entries) == 0 {
+			os.Remove(mockFolder)
+		}
+	}
+
+	sort.SliceStable(m.changes, func(i, j int) bool {
+		if m.changes[i].File != m.changes[j].File {
+			return m.changes[i].File < m.changes[j].File
+		}
+		return m.changes[i].Line < m.changes[j].Line
+	})
+	return m.changes
+}
+
+/////////////
+// HELPERS //
+/////////////
+
+// State accumulated while walking through the directory
+type migrator struct {
+	changes []Change
+
+	/*
+		Interfaces whose mock fields were pointed away from mockery's folder,
+		keyed by the import path of their package and their name.
+	*/
+	repointed map[string]bool
+}
+
+// Whether or not a field was pointed away from the mock of one of the interfaces of the package
+func (m *migrator) isRepointed(packagePath string, interfaceNames []string) bool {
+	if packagePath == "" {
+		return false
+	}
+	for _, name := range interfaceNames {
+		if m.repointed[packagePath+"."+name] {
+			return true
+		}
+	}
+	return false
+}
+
+// Migrate a single file, writing it back if anything changed
+func (m *migrator) migrateFile(fileName string) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		panic(err)
+	}
+	fileString := string(content)
+	changes := []Change{}
+
+	fileString, stripped := helpers.StripLegacy(fileName, fileString)
+	if stripped {
+		changes = append(changes, Change{File: fileName, Message: "removed code generated with the legacy layout"})
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, fileString, parser.ParseComments)
+	if err != nil {
+		panic(err)
+	}
+
+	// The import paths of the packages used by the file, keyed by the name they are used with
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		packagePath := strings.Trim(spec.Path.Value, `"`)
+		if spec.Name != nil {
+			imports[spec.Name.Name] = packagePath
+		} else {
+			imports[path.Base(packagePath)] = packagePath
+		}
+	}
+
+	edits := []helpers.Edit{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		structNode, ok := n.(*ast.StructType)
+		if !ok {
+			return true
+		}
+
+		start, end := fset.Position(structNode.Pos()).Offset, fset.Position(structNode.End()).Offset
+		if !isComponent(fileString[start:end]) {
+			return true
+		}
+		edits = append(edits, migrateOptions(fileString[start:end], start)...)
+
+		for _, field := range structNode.Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			tagStart := fset.Position(field.Tag.Pos()).Offset
+			match := mockPkgTag.FindStringIndex(field.Tag.Value)
+			if match == nil {
+				continue
+			}
+
+			typeString := strings.TrimLeft(fileString[fset.Position(field.Type.Pos()).Offset:fset.Position(field.Type.End()).Offset], "*")
+			packageName, interfaceName, ok := strings.Cut(typeString, ".")
+			if !ok {
+				continue
+			}
+			edits = append(edits, helpers.Edit{Start: tagStart + match[0], End: tagStart + match[1], Text: `pkg:"` + packageName + `_mocks"`})
+
+			interfaceName, _, _ = strings.Cut(interfaceName, "[")
+			if packagePath, ok := imports[packageName]; ok {
+				m.repointed[packagePath+"."+interfaceName] = true
+			}
+		}
+
+		return true
+	})
+
+	for _, e := range edits {
+		changes = append(changes, Change{
+			File:    fileName,
+			Line:    strings.Count(fileString[:e.Start], "\n") + 1,
+			Message: "replaced " + fileString[e.Start:e.End] + " with " + e.Text,
+		})
+	}
+	fileString = helpers.ApplyEdits(fileString, edits)
+
+	if len(changes) > 0 {
+		err = os.WriteFile(fileName, []byte(fileString), 0777)
+		if err != nil {
+			panic(err)
+		}
+	}
+	m.changes = append(m.changes, changes...)
+}
+
+/*
+Find the options of a component struct which need to be renamed. The offset is
+where the struct starts in the file.
+*/
+func migrateOptions(structString string, offset int) []helpers.Edit {
+	edits := []helpers.Edit{}
+	for _, match := range optionPattern.FindAllStringSubmatchIndex(structString, -1) {
+		name, value := structString[match[2]:match[3]], structString[match[4]:match[5]]
+
+		renamed := currentOption(name)
+		if renamed == "generate" {
+			if current, ok := renamedGenerate[value]; ok {
+				value = current
+			}
+		}
+
+		replacement := renamed + "::" + value
+		if replacement != structString[match[0]:match[1]] {
+			edits = append(edits, helpers.Edit{Start: offset + match[0], End: offset + match[1], Text: replacement})
+		}
+	}
+	return edits
+}
+
+// The current name of an option. Options which weren't renamed are returned unchanged.
+func currentOption(name string) string {
+	if renamed, ok := renamedOptions[name]; ok {
+		return renamed
+	}
+	return name
+}
+
+// Whether or not the struct is registered as a component, in any layout
+func isComponent(structString string) bool {
+	for _, match := range optionPattern.FindAllStringSubmatch(structString, -1) {
+		if currentOption(match[1]) == "generate" {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether or not a file was generated by mockery
+func isMockery(fileName string) bool {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		panic(err)
+	}
+	return strings.HasPrefix(string(content), "// Code generated by mockery")
+}
+
+// The names of the types declared in a mockery generated file, each named after the interface it mocks
+func mockTypes(fileName string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), fileName, nil, parser.SkipObjectResolution)
+	if err != nil {
+		panic(err)
+	}
+
+	names := []string{}
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			names = append(names, spec.(*ast.TypeSpec).Name.Name)
+		}
+	}
+	return names
+}
+
+/*
+The import path of the package in a folder, based on the path of the module
+declared by the closest go.mod above it. Folders outside of any module have no
+import path, which is returned as "".
+*/
+func importPath(folder string) string {
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		panic(err)
+	}
+
+	for dir := folder; ; dir = filepath.Dir(dir) {
+		content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			relative, err := filepath.Rel(dir, folder)
+			if err != nil {
+				panic(err)
+			}
+			return path.Join(modfile.ModulePath(content), filepath.ToSlash(relative))
+		}
+
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
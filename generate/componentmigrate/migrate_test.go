@@ -0,0 +1,47 @@
+package componentmigrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mockeryHeader = "// Code generated by mockery v2.38.0. DO NOT EDIT.\n\npackage mocks\n\n"
+
+func TestMigrateMockeryMocks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/app\n\ngo 1.21\n",
+		"store/store.go": "package store\n\ntype Store interface{ Get() }\n\ntype Cache interface{ Get() }\n",
+
+		// Only the mock of the store is pointed away from, the cache and the other store keep theirs
+		"store/mocks/Store.go":       mockeryHeader + "type Store struct{}\n",
+		"store/mocks/Cache.go":       mockeryHeader + "type Cache struct{}\n",
+		"other/store/mocks/Store.go": mockeryHeader + "type Store struct{}\n",
+
+		"service/service.go": "package service\n\nimport \"example.com/app/store\"\n\n" +
+			"type service struct {\n\t// generate::components\n\tstore store.Store `pkg:\"mocks\"`\n}\n",
+	}
+	for name, content := range files {
+		fileName := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fileName), 0777))
+		assert.NoError(t, os.WriteFile(fileName, []byte(content), 0666))
+	}
+
+	changes := Migrate(dir)
+
+	assert.Equal(t, []Change{
+		{File: filepath.Join(dir, "service/service.go"), Line: 7, Message: `replaced pkg:"mocks" with pkg:"store_mocks"`},
+		{File: filepath.Join(dir, "store/mocks/Store.go"), Message: "removed mock generated into the mockery default folder"},
+	}, changes)
+
+	service, err := os.ReadFile(filepath.Join(dir, "service/service.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(service), "store store.Store `pkg:\"store_mocks\"`")
+
+	assert.NoFileExists(t, filepath.Join(dir, "store/mocks/Store.go"))
+	assert.FileExists(t, filepath.Join(dir, "store/mocks/Cache.go"))
+	assert.FileExists(t, filepath.Join(dir, "other/store/mocks/Store.go"))
+}
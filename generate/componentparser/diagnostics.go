@@ -0,0 +1,45 @@
+package componentparser
+
+import "fmt"
+
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+/*
+A problem found while parsing or generating. Warnings don't stop generation,
+errors do. The position fields are only set when the problem can be tied to a
+specific place in a file.
+*/
+type Diagnostic struct {
+	Severity  Severity `json:"severity"`
+	Component string   `json:"component,omitempty"` // ID of the component the diagnostic is for
+	File      string   `json:"file,omitempty"`
+	Line      int      `json:"line,omitempty"`
+	Column    int      `json:"column,omitempty"`
+	Message   string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	position := ""
+	if d.File != "" {
+		position = d.File + ": "
+		if d.Line > 0 {
+			position = fmt.Sprintf("%s:%d:%d: ", d.File, d.Line, d.Column)
+		}
+	}
+	return fmt.Sprintf("%s%s: %s", position, d.Severity, d.Message)
+}
+
+// Record a warning for a component
+func (p *Parser) warn(structData *StructData, message string) {
+	p.Diagnostics = append(p.Diagnostics, Diagnostic{
+		Severity:  SeverityWarning,
+		Component: structData.ID(),
+		File:      structData.StructFile,
+		Message:   message,
+	})
+}
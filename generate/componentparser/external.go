@@ -0,0 +1,168 @@
+package componentparser
+
+import (
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"github.com/flywingedai/components/generate/helpers"
+	"golang.org/x/tools/go/packages"
+)
+
+/*
+Load an interface of another package, such as io.Reader or the client interface
+of an SDK, so that it can be mocked just like the interface of a component. The
+package is resolved from the directory, which must be inside of a module that
+requires it. The returned data only has the options the mocks need, which must
+then be filled in with the mock folder and file.
+*/
+func LoadInterface(dir, importPath, name string) *StructData {
+	pkgs, err := packages.Load(&packages.Config{
+		Dir:  dir,
+		Mode: packages.NeedName + packages.NeedTypes,
+	}, importPath)
+	if err != nil {
+		panic(err)
+	}
+	if len(pkgs) != 1 || len(pkgs[0].Errors) > 0 {
+		panic(fmt.Sprintf("could not load package %s for the mock of %s: %v", importPath, name, pkgs))
+	}
+	pkg := pkgs[0]
+
+	object := pkg.Types.Scope().Lookup(name)
+	if object == nil {
+		panic("package " + importPath + " has no type " + name + " to mock")
+	}
+	named, ok := object.Type().(*types.Named)
+	if !ok {
+		panic(importPath + "." + name + " is not a named type and can't be mocked")
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok {
+		panic(importPath + "." + name + " is not an interface and can't be mocked")
+	}
+	if !object.Exported() {
+		panic(importPath + "." + name + " is not exported and can't be mocked outside of its package")
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	structData := &StructData{
+		Name:          name,
+		Generic:       Fields{},
+		PackageName:   pkg.Name,
+		PackagePath:   pkg.PkgPath,
+		PackageFolder: absDir,
+
+		ScopedNames:  map[string]bool{},
+		TypesPackage: pkg.Types,
+		Imports:      helpers.Imports{},
+
+		Methods: []MethodData{},
+		Options: StructOptions{
+			InterfaceName:    name,
+			InterfacePackage: pkg.Name,
+			InterfacePath:    pkg.PkgPath,
+			Expecters:        []string{},
+			Plugins:          []string{},
+		},
+	}
+
+	for _, scopedName := range pkg.Types.Scope().Names() {
+		structData.ScopedNames[scopedName] = true
+	}
+	structData.addInterface(named, iface)
+
+	return structData
+}
+
+/*
+Add the type parameters and methods of an interface to the data. Types from the
+package of the data are left unqualified, just like they would appear in its
+source, while every other package is qualified and added to the imports.
+*/
+func (s *StructData) addInterface(named *types.Named, iface *types.Interface) {
+	qualifier := func(p *types.Package) string {
+		if p == s.TypesPackage {
+			return ""
+		}
+		s.Imports.Add(newImport(p.Path(), p.Name()))
+		return p.Name()
+	}
+
+	for i := 0; i < named.TypeParams().Len(); i++ {
+		param := named.TypeParams().At(i)
+		s.Generic = append(s.Generic, Field{
+			Name: param.Obj().Name(),
+			Type: types.TypeString(param.Constraint(), qualifier),
+		})
+	}
+
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+		if !method.Exported() {
+			panic(s.PackagePath + "." + s.Name + " has the unexported method " + method.Name() + " and can't be mocked outside of its package")
+		}
+
+		signature := method.Type().(*types.Signature)
+		s.Methods = append(s.Methods, MethodData{
+			Name:    method.Name(),
+			Args:    tupleFields(signature.Params(), signature.Variadic(), qualifier),
+			Returns: tupleFields(signature.Results(), false, qualifier),
+		})
+	}
+}
+
+/*
+Convert the params or results of a signature into fields. Unnamed values are
+named _a0..._aN just like when parsing the source.
+*/
+func tupleFields(tuple *types.Tuple, variadic bool, qualifier types.Qualifier) Fields {
+	fields := Fields{}
+	for i := 0; i < tuple.Len(); i++ {
+		v := tuple.At(i)
+
+		field := Field{Name: v.Name()}
+		if field.Name == "" || field.Name == "_" {
+			field.Name = fmt.Sprintf("_a%d", i)
+		}
+
+		if variadic && i == tuple.Len()-1 {
+			field.Type = "..." + types.TypeString(v.Type().(*types.Slice).Elem(), qualifier)
+		} else {
+			field.Type = types.TypeString(v.Type(), qualifier)
+		}
+
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+/*
+Point the fields whose mocks were inferred with pkg:"-" at the mocks of the
+interfaces of other packages. Their mock package is wherever it was configured,
+so it can't be inferred from the type of the field alone.
+*/
+func (s *StructData) ResolveMocks(mocks []*StructData) {
+	for i, f := range s.Fields {
+		if !f.MockInferred {
+			continue
+		}
+
+		qualifier, name, ok := strings.Cut(strings.TrimPrefix(f.Type, "*"), ".")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(name, "[")
+
+		for _, mock := range mocks {
+			if mock.Options.InterfacePath == s.FileImports[qualifier] && mock.Options.InterfaceName == name {
+				s.Fields[i].MockPkg = mock.Options.MockPackage
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package componentparser
+
+import (
+	"go/ast"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flywingedai/components/generate/helpers"
+	"golang.org/x/tools/go/packages"
+)
+
+/*
+A consumer-side interface extracted from the concrete type of a component
+field tagged with extract:"true". The interface only has the methods the
+component actually calls, and is mocked like a hand-written interface.
+*/
+type Extraction struct {
+	Field    string // Name of the field of the component
+	Params   string // Name of the field of the Params which holds the same type
+	Concrete string // The concrete type, as written in the struct file
+
+	// Whether the fields still have the concrete type in the struct file
+	Retype bool
+
+	// The extracted interface, which lives in the package of the component
+	Interface *StructData
+}
+
+/*
+Extract the interfaces of the fields tagged with extract:"true" for every
+component in the package. Must be called once all the files of the package have
+been parsed, as the methods of a component can be spread across them.
+
+The first time, the concrete type is the type of the field. Once the field has
+been retyped to the interface, the concrete type is kept in the tag instead.
+*/
+func (p *Parser) ExtractInterfaces(pkg *packages.Package) {
+	for _, structData := range p.Structs {
+		if !structData.Options.Generate || structData.PackageFolder != p.PackageFolder {
+			continue
+		}
+
+		object := pkg.Types.Scope().Lookup(structData.Name)
+		if object == nil {
+			continue
+		}
+		structType, ok := object.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+
+		for i, f := range structData.Fields {
+			if f.Extract == "" {
+				continue
+			}
+			if f.MockPkg != "" || f.MockFunc {
+				panic("field " + f.Name + " of " + structData.Name + " in " + structData.StructFile + " can't be extracted and mocked at the same time")
+			}
+
+			var fieldVar *types.Var
+			for j := 0; j < structType.NumFields(); j++ {
+				if structType.Field(j).Name() == f.Name {
+					fieldVar = structType.Field(j)
+				}
+			}
+			if fieldVar == nil {
+				continue
+			}
+
+			extraction := p.extract(pkg, structData, f, fieldVar)
+			structData.Extractions = append(structData.Extractions, extraction)
+			p.Mocks[extraction.Interface.ID()] = extraction.Interface
+
+			// The field is mocked with the mock of the extracted interface
+			mockPackage := path.Base(extraction.Interface.Options.MockFolder)
+			structData.Imports.Add(newImport(structData.PackagePath+"/"+mockPackage, mockPackage))
+			structData.Fields[i].Type = extraction.Interface.Name
+			structData.Fields[i].MockPkg = mockPackage
+			structData.Fields[i].MockNew = "New" + extraction.Interface.Name
+			structData.Fields[i].MockType = extraction.Interface.Name
+		}
+	}
+}
+
+// Extract the interface of a single field
+func (p *Parser) extract(pkg *packages.Package, structData *StructData, f Field, fieldVar *types.Var) *Extraction {
+	extraction := &Extraction{
+		Field:    f.Name,
+		Params:   helpers.ToTitle(f.Name),
+		Concrete: f.Extract,
+		Retype:   f.Extract == "true",
+	}
+	if extraction.Retype {
+		extraction.Concrete = f.Type
+	}
+
+	// The concrete type is resolved with the imports of the struct file
+	var structFile *ast.File
+	for _, file := range pkg.Syntax {
+		if pkg.Fset.Position(file.Pos()).Filename == structData.StructFile {
+			structFile = file
+		}
+	}
+	if structFile == nil {
+		panic("could not find the syntax of " + structData.StructFile)
+	}
+	concrete, err := types.Eval(pkg.Fset, pkg.Types, structFile.Name.End(), extraction.Concrete)
+	if err != nil {
+		panic("could not resolve the type " + extraction.Concrete + " extracted by " + structData.Name + "." + f.Name + ": " + err.Error())
+	}
+	if !concrete.IsType() {
+		panic(extraction.Concrete + " extracted by " + structData.Name + "." + f.Name + " is not a type")
+	}
+	if types.IsInterface(concrete.Type) {
+		panic("field " + f.Name + " of " + structData.Name + " is already an interface and can't be extracted")
+	}
+
+	name := helpers.ToTitle(structData.Name) + helpers.ToTitle(f.Name)
+	mockFolder := filepath.Join(structData.PackageFolder, path.Base(structData.PackageFolder)+"_extracted_mocks")
+	iface := &StructData{
+		Name:          name,
+		Generic:       Fields{},
+		StructFile:    structData.StructFile,
+		PackageName:   structData.PackageName,
+		PackagePath:   structData.PackagePath,
+		PackageFolder: structData.PackageFolder,
+
+		ScopedNames:  structData.ScopedNames,
+		TypesPackage: structData.TypesPackage,
+		Imports:      helpers.Imports{},
+		FileImports:  structData.FileImports,
+		ImportNames:  structData.ImportNames,
+
+		Methods: []MethodData{},
+		Options: StructOptions{
+			InterfaceName:    name,
+			InterfaceFolder:  structData.PackageFolder,
+			InterfacePackage: structData.PackageName,
+			InterfacePath:    structData.PackagePath,
+			InterfaceFile:    structData.StructFile,
+			MockFolder:       mockFolder,
+			Templates:        structData.Options.Templates,
+			Expecters:        []string{},
+			Plugins:          []string{},
+		},
+	}
+
+	// Every method called on the field anywhere in the package is needed
+	called := map[string]bool{}
+	for _, file := range pkg.Syntax {
+		ast.Inspect(file, func(n ast.Node) bool {
+			selector, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			inner, ok := selector.X.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			selection, ok := pkg.TypesInfo.Selections[inner]
+			if ok && selection.Kind() == types.FieldVal && selection.Obj() == fieldVar {
+				called[selector.Sel.Name] = true
+			}
+			return true
+		})
+	}
+
+	/*
+		Types are referenced the same way the struct file does, so an aliased
+		import keeps its alias.
+	*/
+	qualifier := func(typesPackage *types.Package) string {
+		if typesPackage == structData.TypesPackage {
+			return ""
+		}
+		for importName, importPath := range structData.FileImports {
+			if importPath == typesPackage.Path() {
+				iface.Imports.Add(newImport(importPath, importName))
+				return importName
+			}
+		}
+		iface.Imports.Add(newImport(typesPackage.Path(), typesPackage.Name()))
+		return typesPackage.Name()
+	}
+
+	names := []string{}
+	for methodName := range called {
+		names = append(names, methodName)
+	}
+	sort.Strings(names)
+
+	for _, methodName := range names {
+		object, _, _ := types.LookupFieldOrMethod(concrete.Type, true, structData.TypesPackage, methodName)
+		method, ok := object.(*types.Func)
+		if !ok {
+			continue
+		}
+
+		signature := method.Type().(*types.Signature)
+		iface.Methods = append(iface.Methods, MethodData{
+			Name:    methodName,
+			Args:    tupleFields(signature.Params(), signature.Variadic(), qualifier),
+			Returns: tupleFields(signature.Results(), false, qualifier),
+		})
+	}
+
+	if len(iface.Methods) == 0 {
+		p.warn(structData, "field "+f.Name+" of component "+structData.Name+" is extracted but none of its methods are called, so "+name+" will be empty")
+	}
+
+	extraction.Interface = iface
+	return extraction
+}
+
+// The expression of a zero value of the concrete type, used to assert it implements the interface
+func (e *Extraction) ConcreteValue() string {
+	if strings.HasPrefix(e.Concrete, "*") {
+		return "(" + e.Concrete + ")(nil)"
+	}
+	return "*new(" + e.Concrete + ")"
+}
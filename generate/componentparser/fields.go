@@ -14,9 +14,29 @@ type Field struct {
 	Name string
 	Type string
 
+	// Position of the field in the file it was parsed from
+	Line   int
+	Column int
+
 	MockPkg  string
 	MockNew  string
 	MockType string
+
+	// Whether the mock tags were inferred with pkg:"-"
+	MockInferred bool
+
+	// Whether the field is a function mocked with mock:"func"
+	MockFunc bool
+
+	// Whether the field holds the spy of its mock in the tests, from mock:"spy"
+	MockSpy bool
+
+	/*
+		The extract tag of a field whose interface is extracted from its
+		concrete type. Either "true", or the concrete type once the field has
+		been retyped to the extracted interface.
+	*/
+	Extract string
 }
 
 type Fields []Field
@@ -26,12 +46,14 @@ Name: The name of the method.
 Recv: The reciever for the method.
 Args: List of all the args for the method.
 Returns: List of al lthe returns
+Uses: Names selected from the reciever in the body, such as "repo" for s.repo
 */
 type MethodData struct {
 	Name    string
 	Recv    Field
 	Args    Fields
 	Returns Fields
+	Uses    []string
 }
 
 /////////////////////
@@ -112,13 +134,6 @@ func (fields Fields) Generic(append bool) (string, string) {
 	return generic, genericLong
 }
 
-func ReplaceScopedNames(s, packageName string, scopedNames map[string]bool) string {
-	for name := range scopedNames {
-		s = strings.ReplaceAll(s, " "+name, " "+packageName+"."+name)
-	}
-	return s
-}
-
 func (fields Fields) AddPointers(s string, asInterface bool) string {
 	if s == "" {
 		return s
@@ -177,11 +192,12 @@ func ConvertASTFieldList(fileString FileString, node *ast.FieldList) Fields {
 			First, extract any tags that may be present on this field. The
 			components package cares about a "pkg" and "new" tag. These
 			correspond to the mock package name and the new function for
-			that package.
+			that package. Function fields are mocked with a "mock" tag
+			instead, and concrete fields get an interface with "extract".
 		*/
 		fieldString := fileString.Extract(fieldNode)
 
-		for _, tag := range []string{"pkg", "new", "type"} {
+		for _, tag := range []string{"pkg", "new", "type", "mock", "extract"} {
 			tagID := fmt.Sprintf("%s:\"", tag)
 			index := strings.Index(fieldString, tagID)
 			if index >= 0 {
@@ -195,6 +211,11 @@ func ConvertASTFieldList(fileString FileString, node *ast.FieldList) Fields {
 						field.MockNew = fieldString[index : index+endIndex]
 					} else if tag == "type" {
 						field.MockType = fieldString[index : index+endIndex]
+					} else if tag == "mock" {
+						field.MockFunc = fieldString[index:index+endIndex] == "func"
+						field.MockSpy = fieldString[index:index+endIndex] == "spy"
+					} else if tag == "extract" {
+						field.Extract = fieldString[index : index+endIndex]
 					}
 
 				}
@@ -208,9 +229,21 @@ func ConvertASTFieldList(fileString FileString, node *ast.FieldList) Fields {
 			field.Name = fmt.Sprintf("_a%d", i)
 		}
 		field.Type = fileString.Extract(fieldNode.Type)
+		field.Line, field.Column = fileString.Position(fieldNode.Pos())
+
+		// Function mocks are generated from the type itself
+		if field.MockFunc && !strings.HasPrefix(field.Type, "func(") {
+			panic("field " + field.Name + " is tagged mock:\"func\" but its type " + field.Type + " is not a function")
+		}
+
+		// Spies are generated beside the mocks, so the field needs a mock
+		if field.MockSpy && field.MockPkg == "" {
+			panic("field " + field.Name + " is tagged mock:\"spy\" but has no pkg tag for its mock")
+		}
 
 		// Fix the mock tags if exists
 		if field.MockPkg == "-" {
+			field.MockInferred = true
 			split := strings.Split(field.Type, ".")
 			if len(split) < 2 {
 				panic("bad type for auto mock inference " + field.Name + " ")
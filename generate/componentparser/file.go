@@ -5,6 +5,7 @@ import (
 	"go/parser"
 	"go/token"
 	"os"
+	"strings"
 )
 
 // Helper wrapper for strings with some additional helper methods
@@ -42,6 +43,21 @@ func (f FileString) Extract(node ast.Node) string {
 	return string(f[node.Pos()-1 : node.End()-1])
 }
 
+/*
+Determine the line and column of a position in the file. Both are 1 indexed to
+match the go/token package.
+*/
+func (f FileString) Position(pos token.Pos) (int, int) {
+	offset := int(pos) - 1
+	if offset > len(f) {
+		offset = len(f)
+	}
+
+	line := 1 + strings.Count(string(f[:offset]), "\n")
+	column := offset - strings.LastIndex(string(f[:offset]), "\n")
+	return line, column
+}
+
 /*
 Grab the children nodes of the specified type
 */
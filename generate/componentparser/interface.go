@@ -0,0 +1,78 @@
+package componentparser
+
+import (
+	"go/ast"
+	"go/types"
+	"unicode"
+
+	"github.com/flywingedai/components/generate/helpers"
+)
+
+/*
+Parse an interface declaration. Hand-written interfaces, such as ports with no
+single implementing struct, are registered with "generate::mocks" in a comment
+inside of the interface, just like components are. They only get the mock along
+with its expecter chains, so they don't need a struct, Params or Convert
+function. The mockFolder, mockFile, config and templates options work the same
+as they do for components.
+*/
+func (p *Parser) ParseInterface(typeNode *ast.TypeSpec, node *ast.InterfaceType) {
+	name := typeNode.Name.Name
+
+	options := extractOptions(p.FileString.Extract(node))
+	if options["generate"] != "mocks" {
+		return
+	}
+
+	structData := &StructData{
+		Name:          name,
+		Generic:       Fields{},
+		StructFile:    p.File,
+		PackageName:   p.PackageName,
+		PackagePath:   p.PackagePath,
+		PackageFolder: p.PackageFolder,
+
+		ScopedNames:  p.ScopedNames,
+		TypesPackage: p.TypesPackage,
+		Imports:      helpers.Imports{},
+		FileImports:  p.FileImports,
+		ImportNames:  p.ImportNames,
+
+		Methods: []MethodData{},
+		Options: StructOptions{
+			InterfaceName:    name,
+			InterfaceFolder:  p.PackageFolder,
+			InterfacePackage: p.PackageName,
+			InterfacePath:    p.PackagePath,
+			InterfaceFile:    p.File,
+			Expecters:        []string{},
+			Plugins:          []string{},
+		},
+	}
+
+	for option, value := range options {
+		switch option {
+		case "generate":
+		case "mockFolder":
+			structData.Options.MockFolder = value
+		case "mockFile":
+			structData.Options.MockFile = value
+		case "config":
+			structData.Options.Config = value
+		case "templates":
+			structData.Options.Templates = value
+		default:
+			panic("invalid option " + option + " in interface " + p.File + "::" + name)
+		}
+	}
+
+	if !unicode.IsUpper(rune(name[0])) {
+		panic("interface " + name + " in " + p.File + " must be exported to generate its mocks")
+	}
+
+	// The type information has the methods of embedded interfaces as well
+	named := p.TypesPackage.Scope().Lookup(name).Type().(*types.Named)
+	structData.addInterface(named, named.Underlying().(*types.Interface))
+
+	p.Mocks[structData.ID()] = structData
+}
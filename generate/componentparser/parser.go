@@ -2,13 +2,13 @@ package componentparser
 
 import (
 	"go/ast"
+	"go/types"
 	"io/fs"
 	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/flywingedai/components/generate/helpers"
-	"github.com/spf13/cobra"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -19,17 +19,27 @@ StructData objects representing all the structs that should be generated.
 type Parser struct {
 	Args ParserArgs
 
-	Structs        map[string]*StructData
-	PackageImports map[string]map[string]bool // List of all the imports needed for a package
+	Structs map[string]*StructData
+
+	// Hand-written interfaces registered with "generate::mocks"
+	Mocks map[string]*StructData
 
 	ScopedNames map[string]bool // Map of all the scoped names in the package
 
+	// Any warnings found while parsing
+	Diagnostics []Diagnostic
+
 	// Values that get updated as the parser is walking
-	File          string     // Which file is currently being parsed
-	FileString    FileString // The extracted file string corresponding to .File
-	PackageFolder string     // Which package fodler is currently being parsed
-	PackageName   string     // Which package is currently being parsed
-	ModulePath    string     // Go Package Path
+	File          string            // Which file is currently being parsed
+	FileString    FileString        // The extracted file string corresponding to .File
+	FileImports   map[string]string // Imports of the current file keyed by the name they are referenced by
+	Imports       helpers.Imports   // Imports of the current file keyed by path
+	PackageFolder string            // Which package fodler is currently being parsed
+	PackageName   string            // Which package is currently being parsed
+	PackagePath   string            // Import path of the package currently being parsed
+	ImportNames   map[string]string // Package names of the current package imports keyed by import path
+	TypesPackage  *types.Package    // Type information of the package currently being parsed
+	ModulePath    string            // Go Package Path
 
 }
 
@@ -50,11 +60,12 @@ type ParserArgs struct {
 	Match string
 }
 
-func New(cmd *cobra.Command) *Parser {
+func New() *Parser {
 	return &Parser{
-		Args:           ParserArgs{},
-		Structs:        map[string]*StructData{},
-		PackageImports: map[string]map[string]bool{},
+		Args:        ParserArgs{},
+		Structs:     map[string]*StructData{},
+		Mocks:       map[string]*StructData{},
+		Diagnostics: []Diagnostic{},
 	}
 }
 
@@ -79,6 +90,11 @@ func (p *Parser) Parse() {
 		panic(err)
 	}
 
+	// Hand-written interfaces only need their mock options resolved
+	for _, structData := range p.Mocks {
+		resolveMockOptions(structData)
+	}
+
 	// Clean up the data.
 	for key, structData := range p.Structs {
 		if !structData.Options.Generate {
@@ -115,6 +131,16 @@ func (p *Parser) Parse() {
 			panic(err)
 		}
 
+		/*
+			The import path of the interface is resolved relative to the
+			package path, so the interface folder must live in the same module.
+		*/
+		relativeFolder, err := filepath.Rel(structData.PackageFolder, structData.Options.InterfaceFolder)
+		if err != nil {
+			panic(err)
+		}
+		structData.Options.InterfacePath = path.Join(structData.PackagePath, filepath.ToSlash(relativeFolder))
+
 		if structData.Options.InterfaceFile == "" {
 			if structData.Options.InterfaceFolder != structData.PackageFolder {
 				structData.Options.InterfaceFile = path.Join(structData.Options.InterfaceFolder, helpers.ToCamel(structData.Options.InterfaceName)+".go")
@@ -125,28 +151,29 @@ func (p *Parser) Parse() {
 			structData.Options.InterfaceFile = path.Join(structData.Options.InterfaceFolder, structData.Options.InterfaceFile)
 		}
 
-		// Mock management
-		if structData.Options.MockFolder == "" {
-			structData.Options.MockFolder = path.Join(structData.Options.InterfaceFolder, path.Base(structData.Options.InterfaceFolder)+"_mocks")
-		} else if structData.Options.MockFolder == "__package__" {
-			structData.Options.MockFolder = path.Join(structData.PackageFolder, path.Base(structData.PackageFolder)+"_mocks")
-		} else {
-			structData.Options.MockFolder, err = filepath.Abs(structData.Options.MockFolder)
-			if err != nil {
-				panic(err)
-			}
-		}
-		structData.Options.MockPackage = path.Base(structData.Options.MockFolder)
-
-		if structData.Options.MockFile == "" {
-			structData.Options.MockFile = helpers.ToCamel(structData.Options.InterfaceName) + ".go"
-		}
+		resolveMockOptions(structData)
 
 		// The struct must not be exported if the interface name is also the same
 		if structData.Options.InterfaceName == structData.Name {
 			panic("struct " + structData.Name + " in " + structData.StructFile + " has the same interface name!")
 		}
 
+		// Problems which don't stop generation are reported as warnings
+		if len(structData.Methods) == 0 {
+			p.warn(structData, "component "+structData.Name+" has no exported methods so its interface will be empty")
+		}
+		for _, expecter := range structData.Options.Expecters {
+			found := expecter == "-"
+			for _, f := range structData.Fields {
+				if f.Name == expecter && (f.MockPkg != "" || f.MockFunc) {
+					found = true
+				}
+			}
+			if !found {
+				p.warn(structData, "expecter "+expecter+" of component "+structData.Name+" is not a mocked field")
+			}
+		}
+
 	}
 }
 
@@ -161,7 +188,7 @@ func (p *Parser) ParseDir(dir string) {
 	*/
 	pkgs, err := packages.Load(&packages.Config{
 		Dir:  dir,
-		Mode: packages.NeedFiles + packages.NeedImports + packages.NeedName + packages.NeedTypes + packages.NeedSyntax,
+		Mode: packages.NeedFiles + packages.NeedImports + packages.NeedName + packages.NeedTypes + packages.NeedSyntax + packages.NeedTypesInfo,
 	})
 
 	if err != nil {
@@ -174,11 +201,18 @@ func (p *Parser) ParseDir(dir string) {
 		}
 
 		p.PackageName = pkg.Name
+		p.PackagePath = pkg.PkgPath
 		p.PackageFolder, err = filepath.Abs(dir)
 		if err != nil {
 			panic(err)
 		}
 
+		p.ImportNames = map[string]string{}
+		for importPath, importPkg := range pkg.Imports {
+			p.ImportNames[importPath] = importPkg.Name
+		}
+
+		p.TypesPackage = pkg.Types
 		p.ScopedNames = map[string]bool{}
 		for _, name := range pkg.Types.Scope().Names() {
 			p.ScopedNames[name] = true
@@ -197,6 +231,8 @@ func (p *Parser) ParseDir(dir string) {
 			p.ParseFile()
 		}
 
+		// Calls made anywhere in the package decide what gets extracted
+		p.ExtractInterfaces(pkg)
 	}
 
 }
@@ -210,6 +246,8 @@ func (p *Parser) ParseFile() {
 	// Extract the *ast.File for the file and the full file contents
 	file, fileString := readFile(p.File)
 	p.FileString = fileString
+	p.FileImports = map[string]string{}
+	p.Imports = helpers.Imports{}
 
 	/*
 		For every *ast.Node, we parse and accumulate relevant information into
@@ -233,3 +271,37 @@ func (p *Parser) ParseFile() {
 	})
 
 }
+
+/*
+Resolve the default values of the options for the mocks, which are shared by
+components and hand-written interfaces. The interface folder must already be
+resolved.
+*/
+func resolveMockOptions(structData *StructData) {
+	var err error
+
+	// Mock management
+	if structData.Options.MockFolder == "" {
+		structData.Options.MockFolder = path.Join(structData.Options.InterfaceFolder, path.Base(structData.Options.InterfaceFolder)+"_mocks")
+	} else if structData.Options.MockFolder == "__package__" {
+		structData.Options.MockFolder = path.Join(structData.PackageFolder, path.Base(structData.PackageFolder)+"_mocks")
+	} else {
+		structData.Options.MockFolder, err = filepath.Abs(structData.Options.MockFolder)
+		if err != nil {
+			panic(err)
+		}
+	}
+	structData.Options.MockPackage = path.Base(structData.Options.MockFolder)
+
+	if structData.Options.MockFile == "" {
+		structData.Options.MockFile = helpers.ToCamel(structData.Options.InterfaceName) + ".go"
+	}
+
+	// Template management
+	if structData.Options.Templates != "" {
+		structData.Options.Templates, err = filepath.Abs(structData.Options.Templates)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package componentparser
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+/*
+Resolve the named type a field refers to. Pointers and generic instantiations
+are looked through, so "*pkg.Client[int]" resolves to the import path of pkg and
+"Client". Returns ok=false for unnamed types like slices, maps and funcs.
+Local types resolve to the package of the struct itself.
+*/
+func (s *StructData) ResolveType(typeString string) (importPath string, typeName string, ok bool) {
+	expr, err := parser.ParseExpr(typeString)
+	if err != nil {
+		return "", "", false
+	}
+
+	for {
+		switch node := expr.(type) {
+		case *ast.StarExpr:
+			expr = node.X
+			continue
+		case *ast.IndexExpr:
+			expr = node.X
+			continue
+		case *ast.IndexListExpr:
+			expr = node.X
+			continue
+		case *ast.ParenExpr:
+			expr = node.X
+			continue
+
+		case *ast.Ident:
+			return s.PackagePath, node.Name, true
+
+		case *ast.SelectorExpr:
+			qualifier, isIdent := node.X.(*ast.Ident)
+			if !isIdent {
+				return "", "", false
+			}
+			importPath, found := s.FileImports[qualifier.Name]
+			if !found {
+				return "", "", false
+			}
+			return importPath, node.Sel.Name, true
+		}
+
+		return "", "", false
+	}
+}
+
+/*
+Qualify every package level name of the struct's package referenced by a type,
+so the type can be used from another package. The type is rewritten through its
+AST, so "[]User", "map[string]*User" and "func(User) error" all become
+qualified, while "pkg.User" and the names of params and fields are left alone.
+Variadic types like "...User" are supported as well.
+*/
+func (s *StructData) QualifyType(typeString string) string {
+	variadic := strings.HasPrefix(typeString, "...")
+	expr, err := parser.ParseExpr(strings.TrimPrefix(typeString, "..."))
+	if err != nil {
+		panic("could not parse type " + typeString + " of " + s.Name + ": " + err.Error())
+	}
+
+	expr = astutil.Apply(expr, func(c *astutil.Cursor) bool {
+		switch node := c.Node().(type) {
+
+		// Already qualified by a package
+		case *ast.SelectorExpr:
+			return false
+
+		case *ast.Ident:
+
+			// The names of params, results, fields and methods
+			if c.Name() == "Names" {
+				return false
+			}
+
+			if s.ScopedNames[node.Name] {
+				c.Replace(&ast.SelectorExpr{
+					X:   ast.NewIdent(s.PackageName),
+					Sel: ast.NewIdent(node.Name),
+				})
+			}
+		}
+		return true
+	}, nil).(ast.Expr)
+
+	buffer := &bytes.Buffer{}
+	err = format.Node(buffer, token.NewFileSet(), expr)
+	if err != nil {
+		panic(err)
+	}
+
+	if variadic {
+		return "..." + buffer.String()
+	}
+	return buffer.String()
+}
+
+/*
+Split a function type into its params and results, so that a mock can be
+generated for it. Unnamed values are named _a0..._aN and variadic params keep
+their "..." prefix. Panics if the type is not a function.
+*/
+func FuncFields(typeString string) (args Fields, returns Fields) {
+	expr, err := parser.ParseExpr(typeString)
+	if err != nil {
+		panic("could not parse type " + typeString + ": " + err.Error())
+	}
+	funcType, ok := expr.(*ast.FuncType)
+	if !ok {
+		panic("type " + typeString + " is not a function")
+	}
+
+	convert := func(list *ast.FieldList) Fields {
+		fields := Fields{}
+		if list == nil {
+			return fields
+		}
+
+		for _, field := range list.List {
+			buffer := &bytes.Buffer{}
+			err := format.Node(buffer, token.NewFileSet(), field.Type)
+			if err != nil {
+				panic(err)
+			}
+
+			names := []string{}
+			for _, name := range field.Names {
+				names = append(names, name.Name)
+			}
+			if len(names) == 0 {
+				names = append(names, "")
+			}
+
+			for _, name := range names {
+				if name == "" || name == "_" {
+					name = "_a" + strconv.Itoa(len(fields))
+				}
+				fields = append(fields, Field{Name: name, Type: buffer.String()})
+			}
+		}
+		return fields
+	}
+
+	return convert(funcType.Params), convert(funcType.Results)
+}
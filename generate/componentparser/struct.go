@@ -3,8 +3,13 @@ package componentparser
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
+	"path"
+	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/flywingedai/components/generate/helpers"
 )
 
 /*
@@ -18,22 +23,38 @@ type StructData struct {
 	StructFile string // The file the struct was found in
 
 	PackageName   string // The name of the package the struct resides in
+	PackagePath   string // The import path of the package the struct resides in
 	PackageFolder string // The enclosing folder of the struct file
 
+	ParamsName      string // The name of the params type which has the convert function
 	ConvertVar      string // The string that represents the reciever variable in the convert function
 	ConvertFunction string // Full text of the params.Convert function
 
-	ScopedNames map[string]bool // List of names that appear in the package
+	ScopedNames  map[string]bool // List of names that appear in the package
+	TypesPackage *types.Package  `json:"-"` // Type information for the package the struct resides in
+
+	/*
+		All the imports which may be required by the files generated for the
+		component. These are the imports of every file the struct, its methods
+		or its convert function are defined in.
+	*/
+	Imports helpers.Imports
 
 	/*
-		All the imports required for all the files associated with the
-		component. This includes all methods as well.
+		The imports of the file the struct was found in, keyed by the name they
+		are referenced by in that file. Used to resolve the field types.
 	*/
-	Imports map[string]bool
+	FileImports map[string]string
+
+	// The names of all the packages imported by the package of the struct
+	ImportNames map[string]string
 
 	Fields  Fields       // All the fields for this component
 	Methods []MethodData // All the public methods for this component
 
+	// Interfaces extracted from the fields tagged with extract:"true"
+	Extractions []*Extraction
+
 	// Generate flags
 	Options StructOptions
 }
@@ -42,6 +63,24 @@ func (s *StructData) ID() string {
 	return s.PackageFolder + "::" + s.Name
 }
 
+// The import of the package the struct resides in
+func (s *StructData) PackageImport() helpers.Import {
+	return newImport(s.PackagePath, s.PackageName)
+}
+
+// The import of the package of the generated interface
+func (s *StructData) InterfaceImport() helpers.Import {
+	return newImport(s.Options.InterfacePath, s.Options.InterfacePackage)
+}
+
+// Create an import which is only aliased when the name can't be assumed
+func newImport(importPath, name string) helpers.Import {
+	if name == helpers.AssumedName(importPath) {
+		return helpers.Import{Path: importPath}
+	}
+	return helpers.Import{Path: importPath, Alias: name}
+}
+
 /*
 Flags for the components generate function. These fields are set via a struct
 */
@@ -51,6 +90,7 @@ type StructOptions struct {
 	InterfaceName    string // Name of the interface once generated
 	InterfaceFolder  string // Folder for the exported interface to go
 	InterfacePackage string // Name of the package for the generated interface.
+	InterfacePath    string // Import path of the package for the generated interface
 	InterfaceFile    string // File Name for the generated interface to go
 
 	MockFolder  string // Name of the generated mockery folder
@@ -59,6 +99,19 @@ type StructOptions struct {
 
 	SkipTestFile bool // True if the test file should be created.
 
+	/*
+		Whether or not to add a Test$Component_$Method function to the test
+		file for each method which doesn't have one yet.
+	*/
+	ScaffoldTests bool
+
+	/*
+		Whether or not to add a Benchmark$Component_$Method function to the
+		benchmark file beside the test file for each method which doesn't have
+		one yet.
+	*/
+	ScaffoldBenchmarks bool
+
 	/*
 		Whether or not to generate the tests file for this struct in the same
 		package or as a "blackbox" with the "_test" extension. If enabled, the
@@ -72,6 +125,12 @@ type StructOptions struct {
 
 	// Whether or not to create shortcut expecters for each mocked subcomponent
 	Expecters []string
+
+	// Directory of templates overriding the built-in templates
+	Templates string
+
+	// Names of the plugins to run for this component after the built-in steps
+	Plugins []string
 }
 
 // Only call when parsing
@@ -80,13 +139,17 @@ func (p *Parser) CreateBaseStructData(name string) *StructData {
 		Name:          name,
 		Generic:       Fields{},
 		PackageName:   p.PackageName,
+		PackagePath:   p.PackagePath,
 		PackageFolder: p.PackageFolder,
 
-		ScopedNames: p.ScopedNames,
+		ScopedNames:  p.ScopedNames,
+		TypesPackage: p.TypesPackage,
+		Imports:      helpers.Imports{},
 
 		Methods: []MethodData{},
 		Options: StructOptions{
 			Expecters: []string{},
+			Plugins:   []string{},
 		},
 	}
 
@@ -98,24 +161,50 @@ func (p *Parser) CreateBaseStructData(name string) *StructData {
 }
 
 /*
-Parse a GenDecl node and add the data to the Parser. The two node types that are
-relevant are the imports and the structs.
+Parse a GenDecl node and add the data to the Parser. The node types that are
+relevant are the imports, the structs and the interfaces.
 */
 func (p *Parser) ParseGenDecl(node *ast.GenDecl) {
 
 	/*
 		Import case. Make sure the import is captured and added to the
-		parser.PackageImports attribute so that generateed files can include
-		all the needed imports.
+		parser.Imports attribute so that generated files can include all the
+		needed imports.
 	*/
 	if node.Tok == token.IMPORT {
-		_, ok := p.PackageImports[p.PackageName]
-		if !ok {
-			p.PackageImports[p.PackageName] = map[string]bool{}
-		}
-
 		for _, importNode := range FindChildNodes[*ast.ImportSpec](node) {
-			p.PackageImports[p.PackageName][p.FileString.Extract(importNode)] = true
+
+			/*
+				Keep track of the name each import is referenced by in this
+				file. Without an alias, this is the name of the imported package.
+			*/
+			importPath, err := strconv.Unquote(importNode.Path.Value)
+			if err != nil {
+				panic(err)
+			}
+			importName, ok := p.ImportNames[importPath]
+			if !ok {
+				importName = path.Base(importPath)
+			}
+			if importNode.Name != nil {
+				importName = importNode.Name.Name
+			}
+			p.FileImports[importName] = importPath
+
+			// Blank and dot imports can't be referenced by generated code
+			if importName == "_" || importName == "." {
+				continue
+			}
+
+			/*
+				Generated files need an alias whenever the package name can't
+				be assumed from the path.
+			*/
+			alias := ""
+			if importName != helpers.AssumedName(importPath) {
+				alias = importName
+			}
+			p.Imports.Add(helpers.Import{Path: importPath, Alias: alias})
 		}
 	}
 
@@ -127,6 +216,12 @@ func (p *Parser) ParseGenDecl(node *ast.GenDecl) {
 	// Grab the type node so we can grab the name
 	typeNode := FindChildNode[*ast.TypeSpec](node)
 
+	// Interfaces can only be registered for their mocks
+	if interfaceNode, ok := typeNode.Type.(*ast.InterfaceType); ok {
+		p.ParseInterface(typeNode, interfaceNode)
+		return
+	}
+
 	structNodes := FindChildNodes[*ast.StructType](node)
 	if len(structNodes) == 0 {
 		return
@@ -182,8 +277,16 @@ func (p *Parser) PopulateStructData(structData *StructData, node *ast.StructType
 			structData.Options.BlackboxFolder = value
 		case "skipTestFile":
 			structData.Options.SkipTestFile = (value == "true")
+		case "scaffoldTests":
+			structData.Options.ScaffoldTests = (value == "true")
+		case "scaffoldBenchmarks":
+			structData.Options.ScaffoldBenchmarks = (value == "true")
 		case "expecters":
 			structData.Options.Expecters = strings.Split(value, ",")
+		case "templates":
+			structData.Options.Templates = value
+		case "plugins":
+			structData.Options.Plugins = strings.Split(value, ",")
 		default:
 			panic("invalid option " + option + " in struct " + p.File + "::" + structData.Name)
 		}
@@ -196,6 +299,9 @@ func (p *Parser) PopulateStructData(structData *StructData, node *ast.StructType
 	// Loop through all the fields of the node and add them to the structData
 	structData.Fields = ConvertASTFieldList(p.FileString, node.Fields)
 	structData.StructFile = p.File
+	structData.FileImports = p.FileImports
+	structData.Imports.Merge(p.Imports)
+	structData.ImportNames = p.ImportNames
 
 }
 
@@ -247,6 +353,8 @@ func (p *Parser) ParseFuncDecl(node *ast.FuncDecl) {
 			panic("more than one *Params method that returns matching component " + structName + ".")
 		}
 
+		structData.Imports.Merge(p.Imports)
+		structData.ParamsName = CleanType(recv.Type)
 		structData.ConvertVar = recv.Name
 		structData.ConvertFunction = p.FileString.Extract(node.Body)
 		return
@@ -264,11 +372,53 @@ func (p *Parser) ParseFuncDecl(node *ast.FuncDecl) {
 		returns = ConvertASTFieldList(p.FileString, node.Type.Results)
 	}
 
+	/*
+		Blank values can't be referenced by the generated mocks and tests, so
+		they are named _a0..._aN just like unnamed values.
+	*/
+	for _, fields := range []Fields{args, returns} {
+		for i := range fields {
+			if fields[i].Name == "_" {
+				fields[i].Name = "_a" + strconv.Itoa(i)
+			}
+		}
+	}
+
+	structData.Imports.Merge(p.Imports)
 	structData.Methods = append(structData.Methods, MethodData{
 		Name:    node.Name.Name,
 		Recv:    recv,
 		Args:    args,
 		Returns: returns,
+		Uses:    receiverFields(recv.Name, node.Body),
 	})
 
 }
+
+/*
+The names selected from the reciever in the body of a method, in the order
+they first appear. Only direct selections like s.repo are found, so anything
+used through another method of the reciever is missed.
+*/
+func receiverFields(recvName string, body *ast.BlockStmt) []string {
+	uses := []string{}
+	if recvName == "" || recvName == "_" || body == nil {
+		return uses
+	}
+
+	found := map[string]bool{}
+	ast.Inspect(body, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := selector.X.(*ast.Ident)
+		if ok && ident.Name == recvName && !found[selector.Sel.Name] {
+			found[selector.Sel.Name] = true
+			uses = append(uses, selector.Sel.Name)
+		}
+		return true
+	})
+	return uses
+}
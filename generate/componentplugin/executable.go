@@ -0,0 +1,73 @@
+package componentplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/flywingedai/components/generate/componentparser"
+)
+
+/*
+The JSON sent to an executable plugin on stdin. The component is the full
+parser output, with the same field names as componentparser.StructData.
+*/
+type Request struct {
+	Plugin    string                      `json:"plugin"`
+	Component *componentparser.StructData `json:"component"`
+}
+
+/*
+The JSON an executable plugin must write to stdout. Setting the error fails
+the generation, as does exiting with a non-zero status.
+*/
+type Response struct {
+	Files []File `json:"files"`
+	Error string `json:"error,omitempty"`
+}
+
+/*
+A plugin which is a separate executable. The request is written to its stdin
+and the files in its response are written once it exits successfully.
+*/
+type Executable struct {
+	Name string
+	Path string
+}
+
+func (e *Executable) Generate(structData *componentparser.StructData, writer *Writer) error {
+	request, err := json.Marshal(Request{Plugin: e.Name, Component: structData})
+	if err != nil {
+		return err
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command(e.Path)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return errors.New(err.Error() + ": " + message)
+		}
+		return err
+	}
+
+	response := Response{}
+	err = json.Unmarshal(stdout.Bytes(), &response)
+	if err != nil {
+		return errors.New("invalid response from " + e.Path + ": " + err.Error())
+	}
+	if response.Error != "" {
+		return errors.New(response.Error)
+	}
+
+	for _, file := range response.Files {
+		writer.Write(file)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package componentplugin
+
+import (
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/helpers"
+)
+
+/*
+Prefix of the executables used as plugins. A component with plugins::metrics
+runs components-plugin-metrics from the PATH, unless a Go plugin was registered
+with the name metrics.
+*/
+const ExecutablePrefix = "components-plugin-"
+
+/*
+A generator run for every component which enables it with the "plugins::"
+option. Plugins run after all the built-in generation steps, and write their
+files through the writer so they are formatted and tracked like every other
+generated file.
+*/
+type Plugin interface {
+	Generate(structData *componentparser.StructData, writer *Writer) error
+}
+
+// Adapter to use an ordinary function as a Plugin
+type Func func(structData *componentparser.StructData, writer *Writer) error
+
+func (f Func) Generate(structData *componentparser.StructData, writer *Writer) error {
+	return f(structData, writer)
+}
+
+// All the Go plugins registered in this build
+var registered = map[string]Plugin{}
+
+/*
+Register a Go plugin under a name. Custom builds of the components command
+register their plugins before calling generate.Execute(). Panics if the name is
+already taken.
+*/
+func Register(name string, plugin Plugin) {
+	if _, ok := registered[name]; ok {
+		panic("plugin " + name + " is already registered")
+	}
+	registered[name] = plugin
+}
+
+// The names of all the registered Go plugins in sorted order
+func Registered() []string {
+	names := []string{}
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+Find the plugin with the given name. Registered Go plugins take precedence over
+executables. Panics if neither exists.
+*/
+func Lookup(name string) Plugin {
+	if plugin, ok := registered[name]; ok {
+		return plugin
+	}
+
+	executable, err := exec.LookPath(ExecutablePrefix + name)
+	if err != nil {
+		panic("plugin " + name + " is not registered and " + ExecutablePrefix + name + " could not be found: " + err.Error())
+	}
+	return &Executable{Name: name, Path: executable}
+}
+
+// Run all the plugins enabled for a component in the order they were listed
+func Run(session *helpers.Session, structData *componentparser.StructData) {
+	for _, name := range structData.Options.Plugins {
+		err := Lookup(name).Generate(structData, NewWriter(session, structData, name))
+		if err != nil {
+			panic("plugin " + name + " failed for " + structData.Name + " in " + structData.StructFile + ": " + err.Error())
+		}
+	}
+}
+
+////////////
+// WRITER //
+////////////
+
+// A file to be written by a plugin
+type File struct {
+	/*
+		Path of the file. Relative paths are relative to the folder of the
+		component.
+	*/
+	Path string `json:"path"`
+
+	// Name of the package of the file. Defaults to the component's package.
+	Package string `json:"package,omitempty"`
+
+	/*
+		Imports the code may need. Only the imports the code references are
+		added to the file.
+	*/
+	Imports []helpers.Import `json:"imports,omitempty"`
+
+	// The code to add to the generated part of the file
+	Code string `json:"code"`
+}
+
+// Handle passed to plugins for writing the files they generate
+type Writer struct {
+	session    *helpers.Session
+	structData *componentparser.StructData
+	plugin     string
+}
+
+func NewWriter(session *helpers.Session, structData *componentparser.StructData, plugin string) *Writer {
+	return &Writer{session: session, structData: structData, plugin: plugin}
+}
+
+/*
+Add code to the generated region of the plugin in a file. Just like the
+built-in steps, the region is replaced on the first write of each run, so
+several writes to the same file during a run are appended together.
+*/
+func (w *Writer) Write(file File) {
+	fileName := file.Path
+	if !filepath.IsAbs(fileName) {
+		fileName = filepath.Join(w.structData.PackageFolder, fileName)
+	}
+
+	packageName := file.Package
+	if packageName == "" {
+		packageName = w.structData.PackageName
+	}
+
+	imports := helpers.Imports{}
+	for _, i := range file.Imports {
+		imports.Add(i)
+	}
+
+	w.session.WriteToFile(fileName, "plugin:"+w.plugin, file.Code, imports, packageName)
+}
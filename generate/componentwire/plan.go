@@ -0,0 +1,487 @@
+package componentwire
+
+import (
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flywingedai/components/generate/componentgraph"
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/config"
+	"github.com/flywingedai/components/generate/helpers"
+)
+
+/*
+A plan for building every component in dependency order. It is fully resolved
+at generation time, so all the type strings are already qualified for the
+package the wiring container is generated into.
+*/
+type Plan struct {
+	Package     string
+	PackagePath string
+
+	Steps  []*Step        // Every component in the order it must be built
+	Config []*ConfigField // Every dependency provided by AppConfig
+
+	// Import paths needed by the generated file keyed by the name they use
+	Imports map[string]string
+}
+
+// Construction of a single component
+type Step struct {
+	Node *componentgraph.Node
+
+	Field  string // Name of the field on the generated App struct
+	Type   string // Qualified interface type of the component
+	New    string // Qualified New function of the component
+	Params string // Qualified Params type of the component
+
+	Args []*Arg
+
+	/*
+		Whether the component implements the lifecycle.Starter or
+		lifecycle.Stopper interfaces. Components implementing either are
+		registered with the lifecycle manager after the lifecycle components
+		they depend on, directly or through other components.
+	*/
+	Starter       bool
+	Stopper       bool
+	LifecycleDeps []*Step
+}
+
+/*
+A single Params field of a component. Exactly one of Provider and Config is set
+depending on where the value comes from.
+*/
+type Arg struct {
+	Field    string
+	Provider *Step
+	Config   *ConfigField
+}
+
+// A field of the generated AppConfig
+type ConfigField struct {
+	Name string
+	Type string
+}
+
+/*
+The constructor of every component. The new step always generates it with this
+name beside the component, so an overridden new template has to keep it.
+*/
+const constructorName = "New"
+
+/*
+Create the plan for all the parsed components. Every problem found while
+resolving the dependencies is collected, and the generation fails with all of
+them at once.
+*/
+func New(structs map[string]*componentparser.StructData, wire config.WireConfig) *Plan {
+	if wire.Output == "" {
+		panic("wire.output must be set in the components config")
+	}
+
+	outputFolder, err := filepath.Abs(path.Dir(wire.Output))
+	if err != nil {
+		panic(err)
+	}
+
+	plan := &Plan{
+		Package: wire.Package,
+		Steps:   []*Step{},
+		Config:  []*ConfigField{},
+		Imports: map[string]string{},
+	}
+	if plan.Package == "" {
+		plan.Package = path.Base(outputFolder)
+	}
+
+	graph := componentgraph.New(structs)
+	problems := []string{}
+
+	/*
+		The output package path is resolved relative to any of the components,
+		so the output has to live in the same module as them.
+	*/
+	for _, node := range graph.Nodes {
+		relativeFolder, err := filepath.Rel(node.Struct.PackageFolder, outputFolder)
+		if err != nil {
+			panic(err)
+		}
+		plan.PackagePath = path.Join(node.PackagePath, filepath.ToSlash(relativeFolder))
+		break
+	}
+
+	// Determine which components are built by the container
+	excluded := map[string]bool{}
+	for _, name := range wire.Exclude {
+		for _, node := range findOne(graph, name, &problems) {
+			excluded[node.ID] = true
+		}
+	}
+
+	steps := map[string]*Step{}
+	for _, node := range graph.Nodes {
+		if excluded[node.ID] {
+			continue
+		}
+		if len(node.Struct.Generic) > 0 {
+			problems = append(problems, "generic component "+node.ID+" can't be wired. Add it to wire.exclude")
+			continue
+		}
+		if node.Struct.ParamsName == "" {
+			continue
+		}
+
+		step := &Step{
+			Node:    node,
+			Field:   helpers.ToTitle(node.Name),
+			Type:    plan.qualify(node.InterfacePath, node.Struct.Options.InterfacePackage, node.Interface),
+			New:     plan.qualify(node.PackagePath, node.Package, constructorName),
+			Params:  plan.qualify(node.PackagePath, node.Package, node.Struct.ParamsName),
+			Starter: hasLifecycleMethod(node.Struct, "Start"),
+			Stopper: hasLifecycleMethod(node.Struct, "Stop"),
+		}
+		steps[node.ID] = step
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	/*
+		Components with the same name in different packages get a package
+		prefix. The name the package is imported with is used, as packages can
+		share a name as well.
+	*/
+	fieldCounts := map[string]int{}
+	for _, step := range plan.Steps {
+		fieldCounts[step.Field]++
+	}
+	for _, step := range plan.Steps {
+		if fieldCounts[step.Field] > 1 {
+			packageName := step.Node.Package
+			if step.Node.PackagePath != plan.PackagePath {
+				packageName = plan.importName(step.Node.PackagePath, step.Node.Package)
+			}
+			step.Field = helpers.ToTitle(packageName) + step.Field
+		}
+	}
+
+	// Resolve the provider of every Params field
+	for _, step := range plan.Steps {
+		paramsFields, ok := lookupParams(step.Node.Struct)
+		if !ok {
+			problems = append(problems, "could not find the type information of "+step.Node.Package+"."+step.Node.Struct.ParamsName)
+			continue
+		}
+
+		for i := 0; i < paramsFields.NumFields(); i++ {
+			field := paramsFields.Field(i)
+			arg := &Arg{Field: field.Name()}
+			step.Args = append(step.Args, arg)
+
+			provider, useConfig := plan.resolve(graph, steps, wire.Bindings, step, field, &problems)
+			if provider != nil {
+				arg.Provider = provider
+			} else if useConfig {
+				arg.Config = &ConfigField{
+					Name: step.Field + field.Name(),
+					Type: types.TypeString(field.Type(), plan.qualifier),
+				}
+				plan.Config = append(plan.Config, arg.Config)
+			}
+		}
+	}
+
+	plan.sortSteps(&problems)
+	plan.resolveLifecycle()
+
+	if len(problems) > 0 {
+		panic("could not wire components:\n\t" + strings.Join(problems, "\n\t"))
+	}
+
+	return plan
+}
+
+/*
+Determine where the value of a Params field comes from. Explicit bindings win,
+then components implementing the field's interface type. Anything that isn't an
+interface comes from the AppConfig.
+*/
+func (plan *Plan) resolve(
+	graph *componentgraph.Graph,
+	steps map[string]*Step,
+	bindings []config.WireBinding,
+	step *Step,
+	field *types.Var,
+	problems *[]string,
+) (*Step, bool) {
+	fieldName := step.Node.Package + "." + step.Node.Name + " field " + field.Name()
+	fullType := types.TypeString(field.Type(), func(p *types.Package) string { return p.Path() })
+
+	for _, binding := range bindings {
+		matchesField := binding.Component != "" && binding.Field == field.Name() && step.Node.Matches(binding.Component)
+		matchesType := binding.Type != "" && binding.Type == fullType
+		if !matchesField && !matchesType {
+			continue
+		}
+
+		if binding.Provider == config.ConfigProvider {
+			return nil, true
+		}
+		for _, node := range findOne(graph, binding.Provider, problems) {
+			provider, ok := steps[node.ID]
+			if !ok {
+				*problems = append(*problems, fieldName+" is bound to "+binding.Provider+" which is not built by the container")
+				return nil, false
+			}
+			return provider, false
+		}
+		return nil, false
+	}
+
+	// Only interfaces can be provided by other components
+	iface, isInterface := field.Type().Underlying().(*types.Interface)
+	if !isInterface || iface.Empty() {
+		return nil, true
+	}
+
+	candidates := []*Step{}
+	for _, node := range graph.Nodes {
+		provider, ok := steps[node.ID]
+		if !ok || provider == step {
+			continue
+		}
+
+		// The generated interface of a component is always an exact match
+		if node.InterfacePath+"."+node.Interface == fullType {
+			return provider, false
+		}
+
+		componentType := node.Struct.TypesPackage.Scope().Lookup(node.Name)
+		if componentType == nil {
+			continue
+		}
+		if types.Implements(types.NewPointer(componentType.Type()), iface) {
+			candidates = append(candidates, provider)
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], false
+	}
+
+	if len(candidates) == 0 {
+		*problems = append(*problems, fieldName+": no component provides "+fullType+". Add a wire binding, with provider: config to pass it in")
+		return nil, false
+	}
+
+	names := []string{}
+	for _, candidate := range candidates {
+		names = append(names, candidate.Node.Package+"."+candidate.Node.Name)
+	}
+	*problems = append(*problems, fieldName+": "+fullType+" is ambiguous between "+strings.Join(names, ", ")+". Add a wire binding")
+	return nil, false
+}
+
+/*
+Order the steps so every component is built after all of its providers. Ties
+are broken by the component ID so the output is stable.
+*/
+func (plan *Plan) sortSteps(problems *[]string) {
+	sort.Slice(plan.Steps, func(i, j int) bool {
+		return plan.Steps[i].Node.ID < plan.Steps[j].Node.ID
+	})
+
+	built := map[*Step]bool{}
+	ordered := []*Step{}
+	for len(ordered) < len(plan.Steps) {
+		progress := false
+		for _, step := range plan.Steps {
+			if built[step] {
+				continue
+			}
+
+			ready := true
+			for _, arg := range step.Args {
+				if arg.Provider != nil && !built[arg.Provider] {
+					ready = false
+				}
+			}
+
+			if ready {
+				built[step] = true
+				ordered = append(ordered, step)
+				progress = true
+			}
+		}
+
+		if !progress {
+			remaining := []string{}
+			for _, step := range plan.Steps {
+				if !built[step] {
+					remaining = append(remaining, step.Node.Package+"."+step.Node.Name)
+				}
+			}
+			*problems = append(*problems, "dependency cycle between "+strings.Join(remaining, ", "))
+			return
+		}
+	}
+
+	plan.Steps = ordered
+}
+
+/*
+Find the closest lifecycle components each lifecycle component depends on.
+Components which aren't part of the lifecycle are looked through, so a starter
+which depends on a plain component that depends on another starter is still
+started after it.
+*/
+func (plan *Plan) resolveLifecycle() {
+	for _, step := range plan.Steps {
+		if !step.IsLifecycle() {
+			continue
+		}
+
+		visited := map[*Step]bool{}
+		var visit func(current *Step)
+		visit = func(current *Step) {
+			for _, arg := range current.Args {
+				provider := arg.Provider
+				if provider == nil || visited[provider] {
+					continue
+				}
+				visited[provider] = true
+
+				if provider.IsLifecycle() {
+					step.LifecycleDeps = append(step.LifecycleDeps, provider)
+				} else {
+					visit(provider)
+				}
+			}
+		}
+		visit(step)
+	}
+}
+
+// Whether or not the component is registered with the lifecycle manager
+func (step *Step) IsLifecycle() bool {
+	return step.Starter || step.Stopper
+}
+
+/////////////
+// HELPERS //
+/////////////
+
+/*
+Determine if a component has a method matching the lifecycle interfaces. The
+method must have the signature func(context.Context) error.
+*/
+func hasLifecycleMethod(structData *componentparser.StructData, name string) bool {
+	if structData.TypesPackage == nil {
+		return false
+	}
+
+	componentType := structData.TypesPackage.Scope().Lookup(structData.Name)
+	if componentType == nil {
+		return false
+	}
+
+	methods := types.NewMethodSet(types.NewPointer(componentType.Type()))
+	selection := methods.Lookup(structData.TypesPackage, name)
+	if selection == nil {
+		return false
+	}
+
+	signature := selection.Type().(*types.Signature)
+	if signature.Params().Len() != 1 || signature.Results().Len() != 1 {
+		return false
+	}
+
+	param := types.TypeString(signature.Params().At(0).Type(), nil)
+	result := types.TypeString(signature.Results().At(0).Type(), nil)
+	return param == "context.Context" && result == "error"
+}
+
+/*
+Qualify a name from a package for use in the generated file. Packages other than
+the output package are added to the imports.
+*/
+func (plan *Plan) qualify(packagePath, packageName, name string) string {
+	if packagePath == plan.PackagePath {
+		return name
+	}
+	return plan.importName(packagePath, packageName) + "." + name
+}
+
+// Import path of the lifecycle package the generated file always imports
+const lifecyclePath = helpers.ModulePath + "/lifecycle"
+
+/*
+Identifiers used by the code of the wire template. Packages are never imported
+with these names, so neither shadows the other.
+*/
+var reservedNames = map[string]bool{
+	"app":       true,
+	"appConfig": true,
+	"config":    true,
+	"ctx":       true,
+	"lifecycle": true,
+}
+
+/*
+The name a package is imported with in the generated file. Packages sharing
+their name with a package imported before them, or with an identifier of the
+template, get a numbered alias, so user/service and order/service are imported
+as service and service2.
+*/
+func (plan *Plan) importName(packagePath, packageName string) string {
+	if packagePath == lifecyclePath {
+		return "lifecycle"
+	}
+
+	for name, existing := range plan.Imports {
+		if existing == packagePath {
+			return name
+		}
+	}
+
+	name := packageName
+	for i := 2; plan.Imports[name] != "" || reservedNames[name]; i++ {
+		name = packageName + strconv.Itoa(i)
+	}
+	plan.Imports[name] = packagePath
+	return name
+}
+
+// Qualifier for go/types which records the imports of the generated file
+func (plan *Plan) qualifier(p *types.Package) string {
+	qualified := plan.qualify(p.Path(), p.Name(), "")
+	return strings.TrimSuffix(qualified, ".")
+}
+
+// Fetch the fields of the Params struct of a component
+func lookupParams(structData *componentparser.StructData) (*types.Struct, bool) {
+	if structData.TypesPackage == nil {
+		return nil, false
+	}
+
+	paramsType := structData.TypesPackage.Scope().Lookup(structData.ParamsName)
+	if paramsType == nil {
+		return nil, false
+	}
+
+	paramsFields, ok := paramsType.Type().Underlying().(*types.Struct)
+	return paramsFields, ok
+}
+
+// Find exactly one node for a name, recording a problem otherwise
+func findOne(graph *componentgraph.Graph, name string, problems *[]string) []*componentgraph.Node {
+	nodes := graph.Find(name)
+	if len(nodes) != 1 {
+		*problems = append(*problems, "component "+name+" in the wire config must match exactly one component")
+		return nil
+	}
+	return nodes
+}
@@ -0,0 +1,37 @@
+package componentwire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualify(t *testing.T) {
+	plan := &Plan{PackagePath: "example.com/app/cmd/app", Imports: map[string]string{}}
+
+	assert.Equal(t, "New", plan.qualify("example.com/app/cmd/app", "main", "New"))
+	assert.Equal(t, "service.New", plan.qualify("example.com/app/user/service", "service", "New"))
+	assert.Equal(t, "service2.New", plan.qualify("example.com/app/order/service", "service", "New"))
+	assert.Equal(t, "service3.Params", plan.qualify("example.com/app/billing/service", "service", "Params"))
+	assert.Equal(t, "service2.Params", plan.qualify("example.com/app/order/service", "service", "Params"))
+	assert.Equal(t, map[string]string{
+		"service":  "example.com/app/user/service",
+		"service2": "example.com/app/order/service",
+		"service3": "example.com/app/billing/service",
+	}, plan.Imports)
+}
+
+func TestQualifyReservedNames(t *testing.T) {
+	plan := &Plan{PackagePath: "example.com/app/cmd/app", Imports: map[string]string{}}
+
+	// Packages named like the identifiers of the template are aliased, except for the lifecycle package itself
+	assert.Equal(t, "config2.Config", plan.qualify("example.com/app/config", "config", "Config"))
+	assert.Equal(t, "app2.Params", plan.qualify("example.com/app/app", "app", "Params"))
+	assert.Equal(t, "lifecycle2.New", plan.qualify("example.com/app/lifecycle", "lifecycle", "New"))
+	assert.Equal(t, "lifecycle.Manager", plan.qualify("github.com/flywingedai/components/lifecycle", "lifecycle", "Manager"))
+	assert.Equal(t, map[string]string{
+		"config2":    "example.com/app/config",
+		"app2":       "example.com/app/app",
+		"lifecycle2": "example.com/app/lifecycle",
+	}, plan.Imports)
+}
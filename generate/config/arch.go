@@ -0,0 +1,36 @@
+package config
+
+/*
+Architecture rules checked by the arch command. Package patterns are either a
+package name or an import path, with a trailing "/..." matching all the child
+packages.
+*/
+type ArchConfig struct {
+
+	// Fail if any of the components depend on each other in a cycle
+	NoCycles bool `yaml:"noCycles"`
+
+	// Rules which apply to all the components of the matching packages
+	Rules []ArchRule `yaml:"rules"`
+}
+
+type ArchRule struct {
+
+	// The package pattern of the components this rule applies to
+	Package string `yaml:"package"`
+
+	/*
+		If set, the components may only depend on components in their own
+		package or in one of these packages.
+	*/
+	Allow []string `yaml:"allow"`
+
+	// The components may not have any fields with types from these packages
+	Deny []string `yaml:"deny"`
+
+	/*
+		If set, every mock tagged field of the components must have a type from
+		one of these packages.
+	*/
+	MockScope []string `yaml:"mockScope"`
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The default location of the components config file
+const DefaultFile = ".components.yml"
+
+/*
+The components config file. This is separate from the mockery config that can be
+passed into each struct with the "config::" option. It holds the settings for
+the commands that work across all the components of a module.
+*/
+type Config struct {
+	Arch ArchConfig `yaml:"arch"`
+	Wire WireConfig `yaml:"wire"`
+
+	/*
+		Directory of templates overriding the built-in templates for every
+		component. Components can still set their own with "templates::".
+	*/
+	Templates string `yaml:"templates"`
+
+	// Interfaces of other packages which get mocks and expecter chains
+	Mocks []MockConfig `yaml:"mocks"`
+}
+
+/*
+An interface of another package, such as io.Reader, to generate a mock for. The
+mock is generated along with the ExpecterChain and Chain extensions, just like
+the mock of a component, so it can be used by the tests package.
+*/
+type MockConfig struct {
+	Path string `yaml:"path"` // Import path of the package of the interface
+	Name string `yaml:"name"` // Name of the interface
+
+	/*
+		The folder (and package) the mock is generated into, relative to the
+		directory the command is executed in. Fields of this interface tagged
+		with pkg:"-" use this package automatically.
+	*/
+	Folder string `yaml:"folder"`
+
+	// File name of the mock. Defaults to the interface name in camel case.
+	File string `yaml:"file"`
+
+	// The mockery config to use for the mock
+	Config string `yaml:"config"`
+}
+
+/*
+Load the config from a file. A missing file is only an error if the file was
+explicitly requested, otherwise an empty config is returned.
+*/
+func Load(fileName string, required bool) *Config {
+	config := &Config{}
+
+	fileData, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return config
+	} else if err != nil {
+		panic(err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(fileData))
+	decoder.KnownFields(true)
+	err = decoder.Decode(config)
+	if err != nil && !errors.Is(err, io.EOF) {
+		panic("invalid components config " + fileName + ": " + err.Error())
+	}
+
+	return config
+}
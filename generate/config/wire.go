@@ -0,0 +1,36 @@
+package config
+
+// Settings for the wiring container generated by the wire command
+type WireConfig struct {
+
+	// The file to write the generated App struct and BuildApp function to
+	Output string `yaml:"output"`
+
+	// Package of the generated file. Defaults to the base of the output folder
+	Package string `yaml:"package"`
+
+	// Components which should not be built by the wiring container
+	Exclude []string `yaml:"exclude"`
+
+	// Explicit providers for dependencies which can't be resolved on their own
+	Bindings []WireBinding `yaml:"bindings"`
+}
+
+/*
+A binding chooses the provider of a dependency. Set Component and Field to bind
+a single Params field of one component, or Type to bind every Params field with
+that type. Components are referenced the same way as the graph --root flag.
+*/
+type WireBinding struct {
+	Component string `yaml:"component"`
+	Field     string `yaml:"field"`
+
+	// Full type of the dependency in the format $importPath.$TypeName
+	Type string `yaml:"type"`
+
+	// The component providing the dependency, or "config" to add it to AppConfig
+	Provider string `yaml:"provider"`
+}
+
+// Provider value which moves a dependency into the generated AppConfig
+const ConfigProvider = "config"
@@ -8,66 +8,45 @@ import (
 	"github.com/flywingedai/components/generate/templates"
 )
 
-func extendMocks(structData *componentparser.StructData) {
+// The components tests package, used by the mocks and the spies
+var testsImport = helpers.Import{Path: "github.com/flywingedai/components/tests"}
 
-	// Handle generics
-	genericShortAppend, genericLongAppend := structData.Generic.Generic(true)
-	genericShort, genericLong := structData.Generic.Generic(false)
+// The testify mock package, used by the function mocks and the chains
+var mockImport = helpers.Import{Path: "github.com/stretchr/testify/mock"}
 
-	// Start with the Expecterchain definition for this mock
-	dataString := templates.BulkReplace(templates.ExpecterChain, map[string]string{
-		"InterfaceName":      structData.Options.InterfaceName,
-		"GenericShort":       genericShort,
-		"GenericLong":        genericLong,
-		"GenericShortAppend": genericShortAppend,
-		"GenericLongAppend":  genericLongAppend,
-	})
+// Used by the After() and WaitUntil() methods of the chains
+var timeImport = helpers.Import{Path: "time"}
+
+/*
+Extend each of the mock files with the ExpecterChain definition for the mock,
+and a chain definition for each of the methods.
+*/
+func extendMocks(session *helpers.Session, structData *componentparser.StructData) {
 
 	/*
-		Loop through each of the methods and add each of their chain definitions
-		onto the mock file.
+		The ExpecterChain has a method for each method of the interface next to
+		AllowAll() and the _P, _T and _M variants of each method. The chain of
+		each method is a type named after it, beside the AllowAllChain and the
+		ExpecterChain itself.
 	*/
+	methods := map[string]bool{}
 	for _, method := range structData.Methods {
-
-		/*
-			Format the response types. As long as there is some response, we
-			format correctly with an extra space at the beginning.
-		*/
-		responseTypes := method.Returns.AsTypes(true)
-		if responseTypes != "" {
-			responseTypes = " " + responseTypes
-		}
-
-		// Form all the replacement pairs for this method chain
-		pairs := map[string]string{
-			"GenericShort":       genericShort,
-			"GenericLong":        genericLong,
-			"GenericShortAppend": genericShortAppend,
-			"GenericLongAppend":  genericLongAppend,
-
-			"InterfaceName": structData.Options.InterfaceName,
-			"Method":        method.Name,
-			"ArgsInterface": method.Args.AsInterface(false),
-			"Args":          componentparser.ReplaceScopedNames(method.Args.AsArgs(false), structData.PackageName, structData.ScopedNames),
-			"ArgsShort":     method.Args.AsParams(),
-
-			"ReturnsArgs":  method.Returns.AsArgs(false),
-			"ReturnsTypes": responseTypes,
-			"ReturnsShort": method.Returns.AsParams(),
+		methods[method.Name] = true
+	}
+	generated := []string{"AllowAll", "Expecter"}
+	for _, method := range structData.Methods {
+		generated = append(generated, method.Name+"_P", method.Name+"_T", method.Name+"_M")
+	}
+	for _, name := range generated {
+		if methods[name] {
+			panic("the chains of " + structData.Options.InterfaceName + " can't be generated as its method " + name + " clashes with a name generated for the chains")
 		}
-
-		pairs["ArgsShortPointer"] = method.Args.AddPointers(pairs["ArgsShort"], true)
-		pairs["ReturnsArgsPointer"] = method.Returns.AddPointers(pairs["ReturnsArgs"], false)
-		pairs["ReturnsShortPointer"] = method.Returns.AddPointers(pairs["ReturnsShort"], false)
-
-		// Add this chain to the data string
-		dataString += templates.BulkReplace(templates.Chain, pairs)
 	}
 
-	if structData.Imports == nil {
-		structData.Imports = map[string]bool{}
-	}
-	structData.Imports["github.com/flywingedai/components/tests"] = true
-	helpers.WriteToFile(path.Join(structData.Options.MockFolder, structData.Options.MockFile), dataString, structData.Imports, structData.Options.MockPackage)
+	data := templates.NewStructData(structData, structData.Options.MockPackage)
+	dataString := templates.Load(structData.Options.Templates).Execute("extendMock", data)
+
+	imports := structData.Imports.With(testsImport, mockImport, timeImport, structData.PackageImport())
+	session.WriteToFile(path.Join(structData.Options.MockFolder, structData.Options.MockFile), "mock", dataString, imports, structData.Options.MockPackage)
 
 }
@@ -0,0 +1,47 @@
+package generate
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/config"
+	"github.com/flywingedai/components/generate/helpers"
+)
+
+/*
+Load each of the interfaces of other packages listed in the components config,
+along with the options their mocks are generated with. The packages are
+resolved from the directory the generator is run in.
+*/
+func loadExternalMocks(dir string, mocks []config.MockConfig, templatesDir string) []*componentparser.StructData {
+	loaded := []*componentparser.StructData{}
+
+	for i, mock := range mocks {
+		if mock.Path == "" || mock.Name == "" || mock.Folder == "" {
+			panic(fmt.Sprintf("mock %d in the components config needs a path, name and folder", i))
+		}
+
+		structData := componentparser.LoadInterface(dir, mock.Path, mock.Name)
+
+		mockFolder, err := filepath.Abs(mock.Folder)
+		if err != nil {
+			panic(err)
+		}
+		structData.Options.MockFolder = mockFolder
+		structData.Options.MockPackage = path.Base(mockFolder)
+
+		structData.Options.MockFile = mock.File
+		if structData.Options.MockFile == "" {
+			structData.Options.MockFile = helpers.ToCamel(mock.Name) + ".go"
+		}
+
+		structData.Options.Config = mock.Config
+		structData.Options.Templates = templatesDir
+
+		loaded = append(loaded, structData)
+	}
+
+	return loaded
+}
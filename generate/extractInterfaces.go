@@ -0,0 +1,128 @@
+package generate
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/helpers"
+	"github.com/flywingedai/components/generate/templates"
+)
+
+/*
+Write the interfaces extracted from the fields tagged with extract:"true" into
+the component's file. The first time a field is extracted, the field and the
+matching Params field are retyped to the interface, and the concrete type is
+moved into the tag so the interface can be extracted again on later runs.
+*/
+func extractInterfaces(session *helpers.Session, structData *componentparser.StructData) {
+	if len(structData.Extractions) == 0 {
+		return
+	}
+
+	retype := []*componentparser.Extraction{}
+	for _, extraction := range structData.Extractions {
+		if extraction.Retype {
+			retype = append(retype, extraction)
+		}
+	}
+	if len(retype) > 0 {
+		retypeFields(structData, retype)
+	}
+
+	t := templates.Load(structData.Options.Templates)
+	for _, extraction := range structData.Extractions {
+		data := templates.NewStructData(extraction.Interface, structData.PackageName)
+		data.ConcreteValue = extraction.ConcreteValue()
+
+		dataString := t.Execute("extract", data)
+		session.WriteToFile(structData.StructFile, "extract", dataString, extraction.Interface.Imports, structData.PackageName)
+	}
+}
+
+/*
+Point the component field and the Params field at the extracted interface. The
+Params field is only retyped when it has the same concrete type, as anything
+else was written by hand.
+*/
+func retypeFields(structData *componentparser.StructData, extractions []*componentparser.Extraction) {
+	content, err := os.ReadFile(structData.StructFile)
+	if err != nil {
+		panic(err)
+	}
+	fileString := string(content)
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, structData.StructFile, fileString, parser.ParseComments)
+	if err != nil {
+		panic(err)
+	}
+
+	edits := []helpers.Edit{}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		typeSpec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok || (typeSpec.Name.Name != structData.Name && typeSpec.Name.Name != structData.ParamsName) {
+			return false
+		}
+
+		for _, field := range structType.Fields.List {
+			if len(field.Names) == 0 {
+				continue
+			}
+			typeString := fileString[offset(field.Type.Pos()):offset(field.Type.End())]
+
+			for _, extraction := range extractions {
+				name := extraction.Field
+				if typeSpec.Name.Name == structData.ParamsName {
+					name = extraction.Params
+				}
+				if field.Names[0].Name != name || typeString != extraction.Concrete {
+					continue
+				}
+
+				edits = append(edits, helpers.Edit{Start: offset(field.Type.Pos()), End: offset(field.Type.End()), Text: extraction.Interface.Name})
+				if typeSpec.Name.Name == structData.Name && field.Tag != nil {
+					tag, err := strconv.Unquote(field.Tag.Value)
+					if err != nil {
+						panic(err)
+					}
+					tag = replaceTag(tag, "extract", extraction.Concrete)
+					edits = append(edits, helpers.Edit{Start: offset(field.Tag.Pos()), End: offset(field.Tag.End()), Text: "`" + tag + "`"})
+				}
+			}
+		}
+		return false
+	})
+
+	fileString = helpers.ApplyEdits(fileString, edits)
+	err = os.WriteFile(structData.StructFile, []byte(fileString), 0777)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Replace the value of a single key in a struct tag
+func replaceTag(tag, key, value string) string {
+	tagID := key + `:"`
+	start := strings.Index(tag, tagID)
+	if start == -1 {
+		return tag
+	}
+	start += len(tagID)
+
+	end := strings.Index(tag[start:], `"`)
+	if end == -1 {
+		return tag
+	}
+	return tag[:start] + value + tag[start+end:]
+}
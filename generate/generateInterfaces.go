@@ -1,44 +1,22 @@
 package generate
 
 import (
-	"fmt"
-
 	"github.com/flywingedai/components/generate/componentparser"
 	"github.com/flywingedai/components/generate/helpers"
 	"github.com/flywingedai/components/generate/templates"
 )
 
 // Generate an interface based on the struct passed int
-func generateInterface(structData *componentparser.StructData) {
-
-	/*
-		// 	Create each of the methods captured during parsing. Aggregate them all
-		// 	into a single string for readability.
-	*/
-	methodString := ""
-	for j, m := range structData.Methods {
-		methodString += fmt.Sprintf(templates.Method, m.Name, m.Args.AsArgs(false), m.Returns.AsTypes(true))
-		if j != len(structData.Methods)-1 {
-			methodString += "\n"
-		}
-
-	}
-
-	genericShort, genericLong := structData.Generic.Generic(false)
-
-	interfaceString := fmt.Sprintf(templates.Interface, structData.Options.InterfaceName, genericLong, methodString)
-	helpers.WriteToFile(structData.Options.InterfaceFile, interfaceString, structData.Imports, structData.Options.InterfacePackage)
+func generateInterface(session *helpers.Session, structData *componentparser.StructData) {
+	t := templates.Load(structData.Options.Templates)
 
-	interfaceName := structData.Options.InterfaceName
-	if structData.Options.InterfacePackage != structData.PackageName {
-		interfaceName = structData.Options.InterfacePackage + "." + interfaceName
-	}
+	interfaceData := templates.NewStructData(structData, structData.Options.InterfacePackage)
+	interfaceString := t.Execute("interface", interfaceData)
+	session.WriteToFile(structData.Options.InterfaceFile, "interface", interfaceString, structData.Imports.With(structData.PackageImport()), structData.Options.InterfacePackage)
 
-	newString := templates.BulkReplace(templates.New, map[string]string{
-		"GenericShort": genericShort,
-		"GenericLong":  genericLong,
-		"Interface":    interfaceName,
-	})
-	helpers.WriteToFile(structData.StructFile, newString, structData.Imports, structData.PackageName)
+	// The New function always lives beside the component struct
+	newData := templates.NewStructData(structData, structData.PackageName)
+	newString := t.Execute("new", newData)
+	session.WriteToFile(structData.StructFile, "new", newString, structData.Imports.With(structData.InterfaceImport()), structData.PackageName)
 
 }
@@ -0,0 +1,38 @@
+package generate
+
+import (
+	"path"
+
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/helpers"
+	"github.com/flywingedai/components/generate/templates"
+)
+
+/*
+Generate a {{InterfaceName}}Spy beside the mockery mock. Spies don't use any
+expectations, they simply record every call and return whatever was configured
+for each method.
+*/
+func generateSpy(session *helpers.Session, structData *componentparser.StructData) {
+
+	// The helpers of the spy are named after the methods of the interface
+	methods := map[string]bool{}
+	for _, method := range structData.Methods {
+		methods[method.Name] = true
+	}
+	for _, method := range structData.Methods {
+		for _, name := range []string{"SpyLog", method.Name + "_Returns", method.Name + "_Func"} {
+			if methods[name] {
+				panic("the spy of " + structData.Options.InterfaceName + " can't be generated as its method " + name + " clashes with a method of the spy")
+			}
+		}
+	}
+
+	// The spy lives in the mock package so local types are qualified
+	data := templates.NewStructData(structData, structData.Options.MockPackage)
+	dataString := templates.Load(structData.Options.Templates).Execute("spy", data)
+
+	imports := structData.Imports.With(testsImport, structData.PackageImport())
+	session.WriteToFile(path.Join(structData.Options.MockFolder, structData.Options.MockFile), "spy", dataString, imports, structData.Options.MockPackage)
+
+}
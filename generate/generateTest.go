@@ -1,7 +1,9 @@
 package generate
 
 import (
-	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"path"
 	"path/filepath"
@@ -12,8 +14,8 @@ import (
 	"github.com/flywingedai/components/generate/templates"
 )
 
-func generateTest(structData *componentparser.StructData) {
-	genericShort, genericLong := structData.Generic.Generic(false)
+func generateTest(session *helpers.Session, structData *componentparser.StructData) {
+	t := templates.Load(structData.Options.Templates)
 
 	/*
 		The main test file for the component is just the name of the file the
@@ -46,12 +48,8 @@ func generateTest(structData *componentparser.StructData) {
 		packageName += "_test"
 	}
 
-	// Determine how to reference Params
-	paramsPrefix := ""
-	if structData.Options.Blackbox {
-		paramsPrefix += structData.PackageName + "."
-	}
-	paramsString := paramsPrefix + "Params"
+	// Everything in the test file is referenced from the test package
+	data := templates.NewStructData(structData, packageName)
 
 	/*
 		If the file Does not exist, we need to add the InitParams function to
@@ -65,13 +63,9 @@ func generateTest(structData *componentparser.StructData) {
 
 		/*
 			We add to the base file the initParams function with the correct
-			typing handed via the replacement.
+			typing handed via the template.
 		*/
-		fileString += templates.BulkReplace(templates.InitParams, map[string]string{
-			"GenericShort": genericShort,
-			"GenericLong":  genericLong,
-			"ParamsPath":   paramsString,
-		})
+		fileString += t.Execute("initParams", data)
 
 		/*
 			Simply write the file. We don't need the helper as we don't want to
@@ -84,20 +78,17 @@ func generateTest(structData *componentparser.StructData) {
 	}
 
 	/*
-		Now we create the mock struct. The mock struct is an exact replica of
-		the base component struct except we update all the fields tagged with
-		mockable values accordingly
+		The method tests are declared at the top level of the test package, so
+		none of their names may already be declared by the user's code.
 	*/
-	mockString := ""
-	mockString += fmt.Sprintf("type mocks%s struct{\n", genericLong)
-	for _, f := range structData.Fields {
-		if f.MockPkg == "" {
-			mockString += fmt.Sprintf("\t%s %s\n", f.Name, f.Type)
-			continue
+	declared := packageNames(path.Dir(fileName), packageName, fileName)
+	for _, method := range structData.Methods {
+		for _, name := range []string{method.Name + "Args", method.Name + "Returns", method.Name + "Test", "test_" + method.Name, "method_" + method.Name} {
+			if declared[name] {
+				panic("the tests of " + structData.Name + " can't be generated as " + name + ", generated for its method " + method.Name + ", is already declared in package " + packageName)
+			}
 		}
-		mockString += fmt.Sprintf("\t%s *%s\n", f.Name, f.MockPkg+"."+f.MockType)
 	}
-	mockString += "}\n\n"
 
 	/*
 		Now we create the convert function which turns the params into mocks.
@@ -108,94 +99,111 @@ func generateTest(structData *componentparser.StructData) {
 	function = strings.ReplaceAll(function, "&"+structData.Name, "&mocks")
 
 	// We need to handle replacements for each field present in the data
-	for _, f := range structData.Fields {
-		if f.MockPkg == "" {
-			continue
-		}
+	for _, f := range data.Fields {
 
 		// Replace all the reciever values with the new values
 		recv := structData.ConvertVar + "."
-
-		// Determine how to cast to the correct type
-		cast := "*" + fmt.Sprintf("%s.%s", f.MockPkg, f.MockType)
+		upper := helpers.ToTitle(f.Name)
 
 		/*
-			Need upper case version of the name as that should be how the the
-			params store their attributes.
+			Function mocks can't be recovered from the function in the params,
+			so buildMocks sets them after converting instead.
 		*/
-		upper := helpers.ToTitle(f.Name)
+		if f.Func != nil {
+			function = strings.Replace(function, recv+upper, "nil", 1)
+			continue
+		}
+
+		if f.Mock == nil {
+			continue
+		}
+
+		// Determine how to cast to the correct type
+		cast := "*" + f.Mock.Package + "." + f.Mock.Type
+
+		// The params store their attributes with the upper case name
 		function = strings.Replace(function, recv+upper, recv+upper+".("+cast+")", 1)
 	}
-	mockString += fmt.Sprintf("func convert%s(%s %s) *mocks%s %s\n\n", genericLong, structData.ConvertVar, paramsString+genericShort, genericShort, function)
+	data.ConvertVar = structData.ConvertVar
+	data.ConvertBody = function
 
 	/*
-		Now we create the bindings for each of the mockable components which
-		allow us to quickly generated mock functions for them during testing.
+		The template creates the mocks struct, which is an exact replica of the
+		base component struct except all the fields tagged with mockable values
+		use their mocks. Then the convert and buildMocks functions, and finally
+		the mock_$Field() bindings for each of the mocked fields which allow us
+		to quickly generate mock functions for them during testing.
 	*/
+	mockString := t.Execute("test", data)
 
-	// Start with creating each of the subcomponent mocks
-	mockComponents := ""
-	for _, f := range structData.Fields {
-		if f.MockPkg == "" {
-			continue
-		}
-
-		mockComponents += fmt.Sprintf("params.%s = %s.%s(t)\n", helpers.ToTitle(f.Name), f.MockPkg, f.MockNew)
+	imports := structData.Imports.With(helpers.Import{Path: "testing"}, testsImport, mockImport, timeImport, structData.PackageImport(), structData.InterfaceImport())
+	session.WriteToFile(fileName, "test", mockString, imports, packageName)
 
+	// The scaffolded tests reference the generated code, so they come last
+	if structData.Options.ScaffoldTests {
+		scaffoldTests(session, structData, t, data, fileName)
+	}
+	if structData.Options.ScaffoldBenchmarks {
+		scaffoldBenchmarks(session, structData, t, data, fileName)
 	}
 
-	// Then we handle the build function mocks
-	componentPackage := ""
-	interfacePackage := ""
-	if structData.Options.Blackbox {
-		componentPackage = structData.PackageName + "."
-		interfacePackage = structData.Options.InterfacePackage + "."
+}
+
+/*
+The names declared at the top level of the package in the folder. The test
+region of the test file is left out, as it is about to be regenerated.
+*/
+func packageNames(folder, packageName, testFileName string) map[string]bool {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		panic(err)
 	}
-	mockString += templates.BulkReplace(templates.BuildMocks, map[string]string{
-		"GenericShort":     genericShort,
-		"GenericLong":      genericLong,
-		"InterfaceName":    structData.Options.InterfaceName,
-		"InterfacePackage": interfacePackage,
-		"ComponentPackage": componentPackage,
-		"MockFields":       mockComponents,
-	})
-
-	// Finally, we replace all the component mock generation functions
-	for _, f := range structData.Fields {
-		if f.MockPkg == "" {
+
+	names := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		fileName := path.Join(folder, entry.Name())
+		if entry.IsDir() || path.Ext(fileName) != ".go" {
 			continue
 		}
 
-		found := len(structData.Options.Expecters) == 0
-		for _, expecter := range structData.Options.Expecters {
-			if expecter == f.Name {
-				found = true
-			}
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			panic(err)
+		}
+		fileString := string(content)
+		if fileName == testFileName {
+			fileString, _ = helpers.StripLegacy(fileName, fileString)
+			fileString = helpers.StripRegion(fileName, fileString, "test")
 		}
 
-		if !found {
+		file, err := parser.ParseFile(fset, fileName, fileString, parser.SkipObjectResolution)
+		if err != nil {
+			panic("could not parse " + fileName + ": " + err.Error())
+		}
+		if file.Name.Name != packageName {
 			continue
 		}
 
-		// Determine if the mock type has any generic type attached to it
-		genericShort, genericShortAppend := "", ""
-		startIndex, endIndex := strings.Index(f.MockType, "["), strings.Index(f.MockType, "]")
-		if startIndex != -1 && endIndex != -1 {
-			generics := componentparser.ConvertTypeString(f.MockType[startIndex+1 : endIndex])
-			genericShort, _ = generics.Generic(false)
-			genericShortAppend, _ = generics.Generic(true)
-			f.MockType = f.MockType[:startIndex]
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					names[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						names[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							names[name.Name] = true
+						}
+					}
+				}
+			}
 		}
-
-		mockString += templates.BulkReplace(templates.GetMockField, map[string]string{
-			"GenericShort":       genericShort,
-			"GenericShortAppend": genericShortAppend,
-			"FieldName":          f.Name,
-			"MockPackage":        f.MockPkg,
-			"MockType":           f.MockType,
-		})
 	}
-
-	helpers.WriteToFile(fileName, mockString, structData.Imports, packageName)
-
+	return names
 }
@@ -0,0 +1,27 @@
+package generate
+
+import (
+	"github.com/flywingedai/components/generate/componentwire"
+	"github.com/flywingedai/components/generate/helpers"
+	"github.com/flywingedai/components/generate/templates"
+)
+
+/*
+Generate the wiring container from the plan. The App struct holds every
+component and BuildApp constructs them in dependency order.
+*/
+func generateWire(session *helpers.Session, plan *componentwire.Plan, output string, templatesDir string) {
+	wireString := templates.Load(templatesDir).Execute("wire", plan)
+
+	imports := helpers.Imports{}
+	imports.Add(helpers.Import{Path: "github.com/flywingedai/components/lifecycle"})
+	for name, importPath := range plan.Imports {
+		i := helpers.Import{Path: importPath}
+		if name != helpers.AssumedName(importPath) {
+			i.Alias = name
+		}
+		imports.Add(i)
+	}
+	session.WriteToFile(output, "wire", wireString, imports, plan.Package)
+
+}
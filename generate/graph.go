@@ -0,0 +1,56 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/flywingedai/components/generate/componentgraph"
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/spf13/cobra"
+)
+
+func newGraphCmd() *cobra.Command {
+
+	graphCommand := &cobra.Command{}
+
+	graphCommand.Use = "graph $DIRECTORY"
+	graphCommand.Short = "Render the component dependency graph as DOT, Mermaid or JSON"
+	graphCommand.Example = "components graph ./ --format mermaid --package services,handlers"
+	graphCommand.Args = cobra.ExactArgs(1)
+
+	format := graphCommand.Flags().String("format", "dot", "Output format. One of dot, mermaid or json")
+	packages := graphCommand.Flags().StringSlice("package", []string{}, "Only include components in these packages. Accepts package names or import paths ending in /...")
+	roots := graphCommand.Flags().StringSlice("root", []string{}, "Only include components reachable from these components")
+	output := graphCommand.Flags().StringP("output", "o", "", "File to write the graph to. Defaults to stdout")
+
+	graphCommand.Run = func(cmd *cobra.Command, args []string) {
+
+		// Parse the whole directory just like the generate command
+		p := componentparser.New()
+		p.Args.Directory = args[0]
+		p.Parse()
+
+		graph := componentgraph.New(p.Structs)
+
+		// Roots are resolved first so that they can reach outside the packages
+		if len(*roots) > 0 {
+			graph = graph.FilterRoots(*roots)
+		}
+		if len(*packages) > 0 {
+			graph = graph.FilterPackages(*packages)
+		}
+
+		rendered := graph.Render(*format)
+		if *output == "" {
+			fmt.Print(rendered)
+			return
+		}
+
+		err := os.WriteFile(*output, []byte(rendered), 0777)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return graphCommand
+}
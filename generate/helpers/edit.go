@@ -0,0 +1,22 @@
+package helpers
+
+import "sort"
+
+// A replacement of the text between two offsets of a file
+type Edit struct {
+	Start, End int
+	Text       string
+}
+
+/*
+Apply the edits to a file. The edits are made from the back so the offsets of
+the earlier ones stay valid, which means they must not overlap.
+*/
+func ApplyEdits(fileString string, edits []Edit) string {
+	sorted := append([]Edit{}, edits...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start > sorted[j].Start })
+	for _, e := range sorted {
+		fileString = fileString[:e.Start] + e.Text + fileString[e.End:]
+	}
+	return fileString
+}
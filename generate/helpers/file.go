@@ -2,23 +2,64 @@ package helpers
 
 import (
 	"errors"
-	"fmt"
 	"os"
 	"os/exec"
 	"path"
+	"sort"
 	"strings"
 )
 
-const generatedDisclaimer = "// Code below was generated by components. DO NOT EDIT.\n"
-const generatedVersion = "// Component version: v1.2.0\n"
+/*
+The disclaimer used by older versions of the generator. Everything below it was
+generated, so it is removed the first time the file is written to.
+*/
+const legacyDisclaimer = "// Code below was generated by components. DO NOT EDIT.\n"
+
+/*
+The files and regions written to during a single run of the generator. Regions
+are only cleared out on their first write during a session, so every run needs
+a session of its own. A session must not be shared by runs happening at the
+same time, but separate sessions can be used concurrently as long as they
+write to different files.
+*/
+type Session struct {
+
+	// All the files which have been written to already during this session
+	files map[string]bool
+
+	// All the regions, keyed by file and step, regenerated during this session
+	regions map[string]bool
+}
+
+// Start a new generation session
+func NewSession() *Session {
+	return &Session{
+		files:   map[string]bool{},
+		regions: map[string]bool{},
+	}
+}
 
-// Map of all the files which have been regenerated already during this call
-var regeneratedFiles = map[string]bool{}
+// All the files written to during the session in sorted order
+func (s *Session) GeneratedFiles() []string {
+	fileNames := []string{}
+	for fileName := range s.files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	return fileNames
+}
 
-func WriteToFile(
+/*
+Write code into the generated region of a file belonging to a step. The first
+write to a region during a session replaces its content, while later writes
+are appended to it. Regions which don't exist yet are added to the end of the
+file. Everything outside of the regions is left untouched.
+*/
+func (s *Session) WriteToFile(
 	fileName string, // Name of the file we're writing to
+	step string, // The generation step the region belongs to
 	code string, // The code to add to the file
-	imports map[string]bool, // All the imports to include during this write
+	imports Imports, // All the imports the code may need
 	packageName string, // The package name. Needed in case this call would generate a new file
 ) {
 
@@ -47,62 +88,122 @@ func WriteToFile(
 	fileString := string(fileData)
 
 	/*
-		Clear out the part of the file after the disclaimer if this file hase
-		not been regenerated during this call yet
+		Files written by older versions of the generator have everything after
+		the disclaimer generated. That code is replaced by the regions.
 	*/
-	if !regeneratedFiles[fileName] {
-		regeneratedFiles[fileName] = true
-
-		/*
-			If there is a generated disclaimer in the file, we cut off
-			everything after it. If there is no disclaimer, just add a newLine
-			to ensure there is a good spacing before adding the disclaimer.
-		*/
-		index := strings.Index(fileString, generatedDisclaimer)
-		if index != -1 {
-			fileString = fileString[:index+len(generatedDisclaimer)] + generatedVersion
-		} else {
-			fileString += "\n" + generatedDisclaimer + generatedVersion
-		}
+	if !s.files[fileName] {
+		s.files[fileName] = true
 
+		fileString, _ = StripLegacy(fileName, fileString)
 	}
 
-	// Add imports statements below the package if relevant
+	// New files need the package clause before anything else
 	packageID := "package " + packageName + "\n"
-	index := strings.Index(fileString, packageID)
-	if index >= 0 {
-		if len(imports) > 0 {
-			cutIndex := index + len(packageID)
-			importString := "import (\n"
-			for importData := range imports {
-				importString += "\t\"" + importData + "\"\n"
-			}
-			importString += "\n)"
-			fileString = fileString[:cutIndex] + importString + fileString[cutIndex:]
-		}
+	if !strings.Contains(fileString, packageID) {
+		fileString += packageID
+	}
+
+	lines := strings.Split(strings.TrimSuffix(fileString, "\n"), "\n")
+	regions := parseRegions(fileName, lines, true)
+
+	// Either replace the content of the region or append to it
+	content := strings.Split(strings.Trim(code, "\n"), "\n")
+	key := fileName + " " + step
+	existing, ok := regions[step]
+	if ok && s.regions[key] {
+		content = append(append(append([]string{}, lines[existing.begin+1:existing.end]...), ""), content...)
+	}
+	s.regions[key] = true
+
+	regionLines := append([]string{beginMarker(step, content)}, content...)
+	regionLines = append(regionLines, endMarker(step))
+	if ok {
+		lines = append(append(append([]string{}, lines[:existing.begin]...), regionLines...), lines[existing.end+1:]...)
 	} else {
-		fileString += fmt.Sprintf("package %s\n", packageName)
+		lines = append(append(lines, ""), regionLines...)
 	}
+	fileString = strings.Join(lines, "\n") + "\n"
 
-	// Add the code data to the file string
-	fileString += code
+	// Add the imports referenced by the code which the file doesn't have yet
+	fileString = mergeImports(fileName, fileString, code, imports)
 
 	// Try and write the updated file string to the file
-	err = os.WriteFile(fileName, []byte(fileString), 0777)
-	if err != nil {
+	writeFile(fileName, fileString)
+	formatFile(fileName)
+
+}
+
+/*
+Add code to the end of an existing file, outside of any of the generated
+regions. The code belongs to the user from then on, so later runs never change
+it.
+*/
+func (s *Session) AppendToFile(
+	fileName string, // Name of the file we're appending to
+	code string, // The code to add to the file
+	imports Imports, // All the imports the code may need
+) {
+	s.files[fileName] = true
+
+	fileString := strings.TrimRight(readFile(fileName), "\n") + "\n\n" + strings.Trim(code, "\n") + "\n"
+
+	// Add the imports referenced by the code which the file doesn't have yet
+	fileString = mergeImports(fileName, fileString, code, imports)
+
+	writeFile(fileName, fileString)
+	formatFile(fileName)
+}
+
+/*
+Check an existing file before something other than a session overwrites it as
+a whole, like mockery does with its mocks. Panics just like writing to the file
+would if one of its regions was edited by hand or the file was generated by a
+newer version of the generator. Files which don't exist yet are fine.
+*/
+func CheckFile(fileName string) {
+	fileData, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	} else if err != nil {
 		panic(err)
 	}
 
-	/*
-		Run the goimports command on the file. This will automatically format
-		the imports and other basic file parameters.
-	*/
-	cmd := exec.Command("goimports", "-w", fileName)
+	fileString, _ := StripLegacy(fileName, string(fileData))
+	lines := strings.Split(strings.TrimSuffix(fileString, "\n"), "\n")
+	parseRegions(fileName, lines, true)
+}
+
+/*
+Run the goimports command on the file. This will automatically format the
+imports and other basic file parameters. Formatting may change the regions, so
+their checksums are updated afterwards. goimports runs from the folder of the
+file, so it finds the packages of the file's module wherever the generator was
+started from.
+*/
+func formatFile(fileName string) {
+	cmd := exec.Command("goimports", "-w", path.Base(fileName))
+	cmd.Dir = path.Dir(fileName)
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
-	err = cmd.Run()
+	err := cmd.Run()
+	if err != nil {
+		panic(err)
+	}
+
+	sealRegions(fileName)
+}
+
+func readFile(fileName string) string {
+	fileData, err := os.ReadFile(fileName)
 	if err != nil {
 		panic(err)
 	}
+	return string(fileData)
+}
 
+func writeFile(fileName string, fileString string) {
+	err := os.WriteFile(fileName, []byte(fileString), 0777)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,185 @@
+package helpers
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+/*
+An import of a generated file. The alias is only set when the package must be
+referenced by a name other than the one assumed from its path.
+*/
+type Import struct {
+	Path  string `json:"path"`
+	Alias string `json:"alias,omitempty"`
+}
+
+/*
+The name the package is referenced by. Without an alias, this is assumed from
+the path the same way goimports does, so gopkg.in/yaml.v3 is yaml and
+example.com/mod/v2 is mod.
+*/
+func (i Import) Name() string {
+	if i.Alias != "" {
+		return i.Alias
+	}
+	return AssumedName(i.Path)
+}
+
+// The package name assumed for an import path without an alias
+func AssumedName(importPath string) string {
+	name := path.Base(importPath)
+
+	// Major version suffixes are not part of the name
+	if strings.HasPrefix(name, "v") {
+		if _, err := strconv.Atoi(name[1:]); err == nil && path.Dir(importPath) != "." {
+			name = path.Base(path.Dir(importPath))
+		}
+	}
+
+	name = strings.TrimPrefix(name, "go-")
+	if index := strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); index >= 0 {
+		name = name[:index]
+	}
+	return name
+}
+
+// A set of imports keyed by their path
+type Imports map[string]Import
+
+// Add an import to the set. The first alias seen for a path is kept.
+func (imports Imports) Add(i Import) {
+	if _, ok := imports[i.Path]; !ok {
+		imports[i.Path] = i
+	}
+}
+
+// Add every import of another set
+func (imports Imports) Merge(other Imports) {
+	for _, i := range other.Sorted() {
+		imports.Add(i)
+	}
+}
+
+// Copy the set with the extra imports added
+func (imports Imports) With(extra ...Import) Imports {
+	copied := Imports{}
+	for _, i := range imports {
+		copied.Add(i)
+	}
+	for _, i := range extra {
+		copied.Add(i)
+	}
+	return copied
+}
+
+// All the imports sorted by path
+func (imports Imports) Sorted() []Import {
+	sorted := []Import{}
+	for _, i := range imports {
+		sorted = append(sorted, i)
+	}
+	sort.Slice(sorted, func(a, b int) bool {
+		return sorted[a].Path < sorted[b].Path
+	})
+	return sorted
+}
+
+/*
+Merge the imports the generated code needs into the file. Only the imports the
+code actually references are added, and only if the file doesn't already import
+them. Panics if a needed name is already taken by a different import.
+*/
+func mergeImports(fileName, fileString, code string, imports Imports) string {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, fileString, parser.ParseComments)
+	if err != nil {
+		panic("could not parse " + fileName + ": " + err.Error())
+	}
+
+	existing := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			panic(err)
+		}
+
+		name := AssumedName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		existing[name] = importPath
+	}
+
+	referenced := referencedPackages(fileName, code)
+	changed := false
+	for _, i := range imports.Sorted() {
+		name := i.Name()
+		if !referenced[name] {
+			continue
+		}
+
+		if existingPath, ok := existing[name]; ok {
+			if existingPath != i.Path {
+				panic(fileName + " imports " + existingPath + " as " + name + " but the generated code needs " + i.Path + " under the same name")
+			}
+			continue
+		}
+
+		astutil.AddNamedImport(fset, file, i.Alias, i.Path)
+		existing[name] = i.Path
+		changed = true
+	}
+
+	if !changed {
+		return fileString
+	}
+
+	buffer := &bytes.Buffer{}
+	err = format.Node(buffer, fset, file)
+	if err != nil {
+		panic(err)
+	}
+	return buffer.String()
+}
+
+/*
+Find the names of all the packages referenced by a piece of generated code. Any
+selector on an identifier which isn't declared in the code itself may refer to
+a package.
+*/
+func referencedPackages(fileName, code string) map[string]bool {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, "package generated\n"+code, 0)
+	if err != nil {
+		panic("could not parse the code generated for " + fileName + ": " + err.Error())
+	}
+
+	referenced := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := selector.X.(*ast.Ident)
+		if ok && ident.Obj == nil {
+			referenced[ident.Name] = true
+		}
+		return true
+	})
+
+	return referenced
+}
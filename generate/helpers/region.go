@@ -0,0 +1,149 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	regionBegin = "// components:begin "
+	regionEnd   = "// components:end "
+)
+
+/*
+A generated region of a file. Each region belongs to a single generation step
+and is delimited by a begin and an end marker:
+
+	// components:begin $step version=$version checksum=$checksum
+	...
+	// components:end $step
+
+The checksum covers everything between the markers, so any change made to a
+region by hand is caught on the next run instead of being overwritten.
+*/
+type region struct {
+	step     string
+	version  string
+	checksum string
+	begin    int // Index of the line with the begin marker
+	end      int // Index of the line with the end marker
+}
+
+/*
+Find all the regions in the lines of a file. Panics if the markers are
+unbalanced, a step has more than one region, or if verify is set and the
+content of a region does not match its checksum or was generated by a newer
+version of the generator.
+*/
+func parseRegions(fileName string, lines []string, verify bool) map[string]*region {
+	regions := map[string]*region{}
+
+	var current *region
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		position := fmt.Sprintf("%s:%d", fileName, i+1)
+
+		if strings.HasPrefix(trimmed, regionBegin) {
+			fields := strings.Fields(strings.TrimPrefix(trimmed, regionBegin))
+			if len(fields) == 0 {
+				panic(position + ": generated region marker is missing its step")
+			}
+			if current != nil {
+				panic(position + ": generated region " + fields[0] + " begins before region " + current.step + " has ended")
+			}
+			if _, ok := regions[fields[0]]; ok {
+				panic(position + ": generated region " + fields[0] + " appears more than once")
+			}
+
+			current = &region{step: fields[0], begin: i}
+			for _, field := range fields[1:] {
+				if version, ok := strings.CutPrefix(field, "version="); ok {
+					current.version = version
+				}
+				if checksum, ok := strings.CutPrefix(field, "checksum="); ok {
+					current.checksum = checksum
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, regionEnd) {
+			step := strings.TrimSpace(strings.TrimPrefix(trimmed, regionEnd))
+			if current == nil {
+				panic(position + ": generated region " + step + " ends without ever beginning")
+			}
+			if step != current.step {
+				panic(position + ": generated region " + current.step + " is ended by the marker of region " + step)
+			}
+
+			current.end = i
+			regions[current.step] = current
+			current = nil
+		}
+	}
+
+	if current != nil {
+		panic(fmt.Sprintf("%s:%d: generated region %s is never ended", fileName, current.begin+1, current.step))
+	}
+
+	if verify {
+		for _, r := range regions {
+			checkVersion(fmt.Sprintf("%s:%d: generated region %s", fileName, r.begin+1, r.step), r.version)
+			if checksum(lines[r.begin+1:r.end]) != r.checksum {
+				panic(fmt.Sprintf(
+					"%s:%d: generated region %s was edited by hand. Move the changes outside of the region, or delete the whole region to generate it again",
+					fileName, r.begin+1, r.step,
+				))
+			}
+		}
+	}
+
+	return regions
+}
+
+// The checksum of the content of a region
+func checksum(lines []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// The begin marker of a region with the checksum of its content
+func beginMarker(step string, content []string) string {
+	return regionBegin + step + " version=" + CurrentVersion() + " checksum=" + checksum(content)
+}
+
+// The end marker of a region
+func endMarker(step string) string {
+	return regionEnd + step
+}
+
+/*
+Recompute the checksum of every region in a file. Called after the file has
+been formatted, as formatting may change the content of the regions.
+*/
+func sealRegions(fileName string) {
+	fileString := readFile(fileName)
+	lines := strings.Split(fileString, "\n")
+
+	for _, r := range parseRegions(fileName, lines, false) {
+		lines[r.begin] = beginMarker(r.step, lines[r.begin+1:r.end])
+	}
+
+	writeFile(fileName, strings.Join(lines, "\n"))
+}
+
+/*
+Remove the content of the region of a step from a file, keeping its markers.
+Used to look at the code around a region which is about to be regenerated.
+Files without the region are returned unchanged.
+*/
+func StripRegion(fileName, fileString, step string) string {
+	lines := strings.Split(fileString, "\n")
+	r, ok := parseRegions(fileName, lines, false)[step]
+	if !ok {
+		return fileString
+	}
+	return strings.Join(append(append([]string{}, lines[:r.begin+1]...), lines[r.end:]...), "\n")
+}
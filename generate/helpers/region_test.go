@@ -0,0 +1,223 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The lines of a region with a valid checksum
+func regionLines(step string, content ...string) []string {
+	lines := append([]string{beginMarker(step, content)}, content...)
+	return append(lines, endMarker(step))
+}
+
+// Join groups of lines into the lines of a single file
+func fileLines(groups ...[]string) []string {
+	lines := []string{}
+	for _, group := range groups {
+		lines = append(lines, group...)
+	}
+	return lines
+}
+
+func TestParseRegions(t *testing.T) {
+	newer := "v999.0.0"
+	edited := regionLines("mock", "func a() {}")
+	edited[1] = "func b() {}"
+
+	cases := []struct {
+		name     string
+		lines    []string
+		expected map[string][2]int // Begin and end line index of each region
+		panics   string            // Part of the panic message, if it should panic
+	}{
+		{
+			name:     "no regions",
+			lines:    []string{"package a", "", "func a() {}"},
+			expected: map[string][2]int{},
+		},
+		{
+			name: "begin and end pairs",
+			lines: fileLines(
+				[]string{"package a", ""},
+				regionLines("interface", "type A interface{}"),
+				[]string{"", "func user() {}", ""},
+				regionLines("plugin:http", "func a() {}", "", "func b() {}"),
+			),
+			expected: map[string][2]int{"interface": {2, 4}, "plugin:http": {8, 12}},
+		},
+		{
+			name:     "indented markers",
+			lines:    fileLines([]string{"package a"}, []string{"\t" + beginMarker("test", []string{}), "\t" + endMarker("test")}),
+			expected: map[string][2]int{"test": {1, 2}},
+		},
+		{
+			name:   "checksum mismatch is refused",
+			lines:  fileLines([]string{"package a"}, edited),
+			panics: "a.go:2: generated region mock was edited by hand",
+		},
+		{
+			name:   "missing checksum is refused",
+			lines:  []string{"package a", regionBegin + "mock", "func a() {}", endMarker("mock")},
+			panics: "a.go:2: generated region mock was edited by hand",
+		},
+		{
+			name: "newer version is refused",
+			lines: []string{
+				"package a",
+				regionBegin + "mock version=" + newer + " checksum=" + checksum([]string{}),
+				endMarker("mock"),
+			},
+			panics: "generated by components " + newer + ", which is newer than the running version",
+		},
+		{
+			name:   "missing end marker",
+			lines:  []string{"package a", beginMarker("mock", []string{"func a() {}"}), "func a() {}"},
+			panics: "a.go:2: generated region mock is never ended",
+		},
+		{
+			name:   "end marker without a begin",
+			lines:  []string{"package a", "func a() {}", endMarker("mock")},
+			panics: "a.go:3: generated region mock ends without ever beginning",
+		},
+		{
+			name:   "nested regions",
+			lines:  []string{"package a", beginMarker("mock", nil), beginMarker("spy", nil), endMarker("spy"), endMarker("mock")},
+			panics: "a.go:3: generated region spy begins before region mock has ended",
+		},
+		{
+			name:   "mismatched end marker",
+			lines:  []string{"package a", beginMarker("mock", nil), endMarker("spy")},
+			panics: "a.go:3: generated region mock is ended by the marker of region spy",
+		},
+		{
+			name:   "duplicate regions",
+			lines:  fileLines([]string{"package a"}, regionLines("mock"), regionLines("mock")),
+			panics: "a.go:4: generated region mock appears more than once",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.panics != "" {
+				assert.Contains(t, panicValue(c.lines), c.panics)
+				return
+			}
+
+			regions := parseRegions("a.go", c.lines, true)
+			found := map[string][2]int{}
+			for step, r := range regions {
+				assert.Equal(t, step, r.step)
+				found[step] = [2]int{r.begin, r.end}
+			}
+			assert.Equal(t, c.expected, found)
+		})
+	}
+}
+
+// The value parseRegions panics with for the lines, or "" if it doesn't panic
+func panicValue(lines []string) (value string) {
+	defer func() {
+		if r := recover(); r != nil {
+			value = fmt.Sprint(r)
+		}
+	}()
+	parseRegions("a.go", lines, true)
+	return ""
+}
+
+func TestParseRegionsWithoutVerify(t *testing.T) {
+	lines := fileLines([]string{"package a"}, regionLines("mock", "func a() {}"))
+	lines[2] = "func b() {}"
+
+	// Sealing has to read the regions which formatting just changed
+	regions := parseRegions("a.go", lines, false)
+	assert.Equal(t, 1, regions["mock"].begin)
+	assert.Equal(t, 3, regions["mock"].end)
+}
+
+func TestStripRegion(t *testing.T) {
+	lines := fileLines([]string{"package a", ""}, regionLines("test", "func a() {}"), []string{"", "func user() {}"})
+	stripped := fileLines([]string{"package a", ""}, []string{lines[2], endMarker("test")}, []string{"", "func user() {}"})
+
+	assert.Equal(t, strings.Join(stripped, "\n"), StripRegion("a.go", strings.Join(lines, "\n"), "test"))
+	assert.Equal(t, strings.Join(lines, "\n"), StripRegion("a.go", strings.Join(lines, "\n"), "mock"), "files without the region are unchanged")
+}
+
+func TestSealRegions(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "a.go")
+	lines := fileLines([]string{"package a", ""}, regionLines("mock", "func a() {}"), []string{"", "func user() {}", ""})
+	lines[3] = "func a() { }"
+	writeFile(fileName, strings.Join(lines, "\n"))
+
+	sealRegions(fileName)
+
+	sealed := strings.Split(readFile(fileName), "\n")
+	assert.Equal(t, beginMarker("mock", []string{"func a() { }"}), sealed[2])
+	assert.Equal(t, append(lines[:2:2], lines[3:]...), append(sealed[:2:2], sealed[3:]...), "only the begin marker changes")
+	assert.NotPanics(t, func() { parseRegions(fileName, sealed, true) })
+}
+
+func TestSessionRegions(t *testing.T) {
+	if _, err := exec.LookPath("goimports"); err != nil {
+		t.Skip("goimports is needed to write files")
+	}
+
+	fileName := filepath.Join(t.TempDir(), "a", "a.go")
+	read := func() string {
+		content, err := os.ReadFile(fileName)
+		assert.NoError(t, err)
+		return string(content)
+	}
+
+	// The first write of a session creates the file and the region, formatted by goimports
+	session := NewSession()
+	session.WriteToFile(fileName, "mock", "func a() {}", Imports{}, "a")
+	assert.Equal(t, "package a\n\n"+strings.Join(regionLines("mock", "func a() {}", ""), "\n")+"\n", read())
+
+	// Later writes during the same session append to the region
+	session.WriteToFile(fileName, "mock", "func b() {}", Imports{}, "a")
+	assert.Contains(t, read(), strings.Join(regionLines("mock", "func a() {}", "", "func b() {}", ""), "\n"))
+
+	// Code appended outside of the regions belongs to the user
+	session.AppendToFile(fileName, "func user() {}", Imports{})
+	assert.True(t, strings.HasSuffix(read(), endMarker("mock")+"\n\nfunc user() {}\n"))
+	assert.Equal(t, []string{fileName}, session.GeneratedFiles())
+
+	// A new session replaces the region and leaves the user's code alone
+	NewSession().WriteToFile(fileName, "mock", "func c() {}", Imports{}, "a")
+	assert.Equal(t, "package a\n\n"+strings.Join(regionLines("mock", "func c() {}", ""), "\n")+"\n\nfunc user() {}\n", read())
+
+	// Regions edited by hand are never overwritten
+	writeFile(fileName, strings.Replace(read(), "func c() {}", "func d() {}", 1))
+	assert.PanicsWithValue(t,
+		fileName+":3: generated region mock was edited by hand. Move the changes outside of the region, or delete the whole region to generate it again",
+		func() { NewSession().WriteToFile(fileName, "mock", "func c() {}", Imports{}, "a") },
+	)
+}
+
+func TestCheckFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "a.go")
+	assert.NotPanics(t, func() { CheckFile(fileName) }, "missing files can be written")
+
+	writeFile(fileName, strings.Join(fileLines([]string{"package a"}, regionLines("mock", "func a() {}")), "\n"))
+	assert.NotPanics(t, func() { CheckFile(fileName) })
+
+	newer := "v999.0.0"
+	writeFile(fileName, strings.Join([]string{
+		"package a",
+		regionBegin + "mock version=" + newer + " checksum=" + checksum([]string{}),
+		endMarker("mock"),
+	}, "\n"))
+	assert.PanicsWithValue(t,
+		fileName+":2: generated region mock was generated by components "+newer+", which is newer than the running version "+CurrentVersion()+
+			". Refusing to overwrite it with an older layout. Upgrade with `go install "+ModulePath+"@"+newer+"`",
+		func() { CheckFile(fileName) },
+	)
+}
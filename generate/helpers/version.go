@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+/*
+Version of the generator when it is built from source. Installed binaries
+report the version of the module they were installed from instead.
+*/
+const Version = "v1.3.0"
+
+// Import path of the components module
+const ModulePath = "github.com/flywingedai/components"
+
+// The marker older versions of the generator wrote below the legacy disclaimer
+const legacyVersion = "// Component version: "
+
+/*
+The version of the generator currently running. This is the version of the
+components module from the build info when it is a release, otherwise it falls
+back to Version. Pseudo versions are ignored, as a build from an untagged
+commit would otherwise appear older than every release.
+*/
+func CurrentVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Version
+	}
+
+	modules := append([]*debug.Module{&info.Main}, info.Deps...)
+	for _, module := range modules {
+		if module.Path != ModulePath || module.Replace != nil {
+			continue
+		}
+		if semver.IsValid(module.Version) && semver.Prerelease(module.Version) == "" && semver.Build(module.Version) == "" {
+			return module.Version
+		}
+	}
+
+	return Version
+}
+
+/*
+Panic if generated output was produced by a newer version of the generator
+than the one currently running. Overwriting it could silently drop features
+the newer layout depends on. Output without a valid version is left alone.
+*/
+func checkVersion(what, generated string) {
+	current := CurrentVersion()
+	if !semver.IsValid(generated) || semver.Compare(generated, current) <= 0 {
+		return
+	}
+
+	panic(fmt.Sprintf(
+		"%s was generated by components %s, which is newer than the running version %s. Refusing to overwrite it with an older layout. Upgrade with `go install %s@%s`",
+		what, generated, current, ModulePath, generated,
+	))
+}
+
+/*
+Remove the code generated by older versions of the generator, which is
+everything after the legacy disclaimer. Returns whether anything was removed.
+*/
+func StripLegacy(fileName, fileString string) (string, bool) {
+	index := strings.Index(fileString, legacyDisclaimer)
+	if index == -1 {
+		return fileString, false
+	}
+
+	// The version was written on the line right after the disclaimer
+	rest := fileString[index+len(legacyDisclaimer):]
+	if generated, ok := strings.CutPrefix(rest, legacyVersion); ok {
+		generated, _, _ = strings.Cut(generated, "\n")
+		checkVersion(fileName+": generated code", strings.TrimSpace(generated))
+	}
+
+	return strings.TrimRight(fileString[:index], "\n") + "\n", true
+}
@@ -1,9 +1,12 @@
 package generate
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/config"
 	"github.com/spf13/cobra"
 )
 
@@ -23,48 +26,56 @@ func NewRootCmd() *cobra.Command {
 	baseCommand.Short = "Generate mock objects for your Golang interfaces using mockery, and then support component based testing and structure"
 	baseCommand.Example = "components $DIRECTORY"
 
-	// Create the main run command
-	baseCommand.Run = func(cmd *cobra.Command, args []string) {
+	/*
+		The directory is validated inside of the run command. Setting the args
+		explicitly stops cobra from treating the directory as a subcommand.
+	*/
+	baseCommand.Args = cobra.ArbitraryArgs
 
-		// Create the parser according to the directory specified
-		p := componentparser.New(cmd)
+	// The components config is shared between all the subcommands
+	baseCommand.PersistentFlags().StringP("config", "c", config.DefaultFile, "The components config file")
 
+	// Create the main run command
+	baseCommand.RunE = func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
-			panic("Invalid usage - " + cmd.Example)
-		}
-		p.Args.Directory = args[0]
-
-		// Parse all files in the path specified
-		p.Parse()
-
-		// Create the interface files for each of the structs that were found
-		for _, structData := range p.Structs {
-			generateInterface(structData)
-		}
-
-		// Run the mockery command for each of the structs that were found
-		for _, structData := range p.Structs {
-			callMockery(structData)
+			return errors.New("invalid usage - " + cmd.Example)
 		}
 
-		/*
-			Extend each of the mock files with additional functionality for
-			components. Simply adds some extra data to the end of each generated
-			mock file
-		*/
-		for _, structData := range p.Structs {
-			extendMocks(structData)
-		}
-
-		// Create test files for each of the structs
-		for _, structData := range p.Structs {
-			if structData.Options.SkipTestFile {
-				continue
+		// Errors past this point are not usage errors
+		cmd.SilenceUsage = true
+
+		componentsConfig := loadConfig(cmd)
+		result, err := Run(cmd.Context(), Config{
+			Directory: args[0],
+			Templates: componentsConfig.Templates,
+			Mocks:     componentsConfig.Mocks,
+		})
+		for _, diagnostic := range result.Diagnostics {
+			if diagnostic.Severity != componentparser.SeverityError {
+				fmt.Fprintln(os.Stderr, diagnostic.String())
 			}
-			generateTest(structData)
 		}
-
+		return err
 	}
 
+	// Add all the subcommands which work across every component
+	baseCommand.AddCommand(newGraphCmd())
+	baseCommand.AddCommand(newArchCmd())
+	baseCommand.AddCommand(newWireCmd())
+	baseCommand.AddCommand(newMigrateCmd())
+	baseCommand.AddCommand(newVersionCmd())
+
 	return baseCommand
 }
+
+/*
+Load the components config passed in with the --config flag. It is only an
+error for the file to be missing if the flag was set explicitly.
+*/
+func loadConfig(cmd *cobra.Command) *config.Config {
+	fileName, err := cmd.Flags().GetString("config")
+	if err != nil {
+		panic(err)
+	}
+	return config.Load(fileName, cmd.Flags().Changed("config"))
+}
@@ -0,0 +1,62 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/flywingedai/components/generate/componentmigrate"
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCmd() *cobra.Command {
+
+	migrateCommand := &cobra.Command{}
+
+	migrateCommand.Use = "migrate $DIRECTORY"
+	migrateCommand.Short = "Rewrite code written for older versions of the generator and regenerate it in the current format"
+	migrateCommand.Example = "components migrate ./"
+	migrateCommand.Args = cobra.ExactArgs(1)
+
+	skipGenerate := migrateCommand.Flags().Bool("skip-generate", false, "Only rewrite the files without regenerating the components afterwards")
+
+	migrateCommand.RunE = func(cmd *cobra.Command, args []string) error {
+
+		// Errors past this point are not usage errors
+		cmd.SilenceUsage = true
+
+		changes, err := migrate(args[0])
+		for _, change := range changes {
+			fmt.Println(change.String())
+		}
+		if err != nil || *skipGenerate {
+			return err
+		}
+
+		// Fill in everything that was removed using the current layout
+		componentsConfig := loadConfig(cmd)
+		result, err := Run(cmd.Context(), Config{
+			Directory: args[0],
+			Templates: componentsConfig.Templates,
+			Mocks:     componentsConfig.Mocks,
+		})
+		for _, diagnostic := range result.Diagnostics {
+			if diagnostic.Severity != componentparser.SeverityError {
+				fmt.Fprintln(os.Stderr, diagnostic.String())
+			}
+		}
+		return err
+	}
+
+	return migrateCommand
+}
+
+// Run the migration, converting any panic into an error
+func migrate(dir string) (changes []componentmigrate.Change, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(r)
+		}
+	}()
+	return componentmigrate.Migrate(dir), nil
+}
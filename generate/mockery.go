@@ -3,45 +3,45 @@ package generate
 import (
 	"os"
 	"os/exec"
+	"path"
 
 	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/helpers"
 )
 
 func callMockery(structData *componentparser.StructData) {
-	/*
-		Switch up the base directory for the interfaces to match the package
-		folder for the struct in question.
-	*/
-	originalDir, err := os.Getwd()
-	if err != nil {
-		panic(err)
-	}
 
-	err = os.Chdir(structData.Options.InterfaceFolder)
-	if err != nil {
-		panic(err)
-	}
+	// Mockery replaces the whole mock file, so refuse before the regions of a newer version are lost
+	helpers.CheckFile(path.Join(structData.Options.MockFolder, structData.Options.MockFile))
 
-	// Run the tailored mockery command for that struct
-	mockeryCommand := exec.Command("mockery",
+	args := []string{
 		"--name", structData.Options.InterfaceName,
 		"--filename", structData.Options.MockFile,
 		"--output", structData.Options.MockFolder,
 		"--outpkg", structData.Options.MockPackage,
 		"--config", structData.Options.Config,
 		"--with-expecter",
-	)
+	}
+
+	// Interfaces of other packages have no folder, so mockery finds them by their import path instead
+	if structData.Options.InterfaceFolder == "" {
+		args = append(args, "--srcpkg", structData.Options.InterfacePath)
+	}
+
+	// Run the tailored mockery command for that struct
+	mockeryCommand := exec.Command("mockery", args...)
+
+	/*
+		Run mockery from the package folder of the struct in question. Only the
+		mockery process changes directory, so the working directory of the
+		generator itself is left alone.
+	*/
+	mockeryCommand.Dir = structData.Options.InterfaceFolder
 
 	// Set output so the mockery output is viewable
 	mockeryCommand.Stderr = os.Stderr
 	mockeryCommand.Stdout = os.Stdout
-	err = mockeryCommand.Run()
-	if err != nil {
-		panic(err)
-	}
-
-	// Switch back to the original directory after the mockery call is made
-	err = os.Chdir(originalDir)
+	err := mockeryCommand.Run()
 	if err != nil {
 		panic(err)
 	}
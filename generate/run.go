@@ -0,0 +1,230 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/componentplugin"
+	"github.com/flywingedai/components/generate/config"
+	"github.com/flywingedai/components/generate/helpers"
+)
+
+// Settings for a single run of the generator
+type Config struct {
+
+	/*
+		The directory to run the generator in. All child folders will be walked
+		through automatically.
+	*/
+	Directory string
+
+	/*
+		Directory of templates overriding the built-in templates. Components
+		with their own "templates::" option use that instead.
+	*/
+	Templates string
+
+	// Interfaces of other packages to generate mocks for
+	Mocks []config.MockConfig
+}
+
+/*
+Everything produced by a run of the generator. Files and Components are both
+sorted so results can be compared between runs. Mocks holds the interfaces
+mocked without a component: the interfaces of other packages in the order they
+were configured, followed by the "generate::mocks" interfaces sorted by id.
+*/
+type Result struct {
+	Files       []GeneratedFile
+	Components  []*componentparser.StructData
+	Mocks       []*componentparser.StructData
+	Diagnostics []componentparser.Diagnostic
+}
+
+// A file written by the generator along with its final contents
+type GeneratedFile struct {
+	Path    string
+	Content []byte
+}
+
+/*
+Run the generator as a library. This does exactly what the components command
+does, but returns errors instead of exiting. The context is checked between
+each of the generation steps. Even when an error is returned, the result holds
+everything that was generated before the error occurred.
+
+Each call keeps track of the files it wrote on its own and never changes the
+working directory, so Run can be called from several goroutines at once. The
+calls must not generate into the same directories though, as nothing stops
+them from writing the same files at the same time.
+*/
+func Run(ctx context.Context, config Config) (result *Result, err error) {
+	session := helpers.NewSession()
+	result = &Result{
+		Files:       []GeneratedFile{},
+		Components:  []*componentparser.StructData{},
+		Mocks:       []*componentparser.StructData{},
+		Diagnostics: []componentparser.Diagnostic{},
+	}
+
+	/*
+		The parser and the generation steps report problems by panicking. Those
+		panics are converted into an error and an error diagnostic here.
+	*/
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(r)
+			result.Diagnostics = append(result.Diagnostics, componentparser.Diagnostic{
+				Severity: componentparser.SeverityError,
+				Message:  err.Error(),
+			})
+		}
+
+		files, readErr := readGeneratedFiles(session)
+		result.Files = files
+		if err == nil {
+			err = readErr
+		}
+	}()
+
+	if config.Directory == "" {
+		return result, errors.New("generate: no directory specified")
+	}
+
+	// Parse all files in the path specified
+	p := componentparser.New()
+	p.Args.Directory = config.Directory
+	p.Parse()
+
+	for _, structData := range p.Structs {
+		if structData.Options.Templates == "" {
+			structData.Options.Templates = config.Templates
+		}
+		result.Components = append(result.Components, structData)
+	}
+	sort.Slice(result.Components, func(i, j int) bool {
+		return result.Components[i].ID() < result.Components[j].ID()
+	})
+	result.Diagnostics = append(result.Diagnostics, p.Diagnostics...)
+
+	/*
+		The interfaces of other packages are mocked in the order they were
+		configured, followed by the hand-written interfaces.
+	*/
+	result.Mocks = loadExternalMocks(config.Directory, config.Mocks, config.Templates)
+	interfaces := []*componentparser.StructData{}
+	for _, structData := range p.Mocks {
+		if structData.Options.Templates == "" {
+			structData.Options.Templates = config.Templates
+		}
+		interfaces = append(interfaces, structData)
+	}
+	sort.Slice(interfaces, func(i, j int) bool {
+		return interfaces[i].ID() < interfaces[j].ID()
+	})
+	result.Mocks = append(result.Mocks, interfaces...)
+
+	// Fields inferred with pkg:"-" use the mocks wherever they were generated
+	for _, structData := range result.Components {
+		structData.ResolveMocks(result.Mocks)
+	}
+
+	/*
+		Interfaces extracted from concrete fields must be written before they
+		can be mocked along with the other interfaces.
+	*/
+	for _, structData := range result.Components {
+		extractInterfaces(session, structData)
+	}
+
+	/*
+		Mocks without a component don't depend on any of the components, but
+		the component tests may depend on them, so they are generated first.
+	*/
+	mockSteps := []func(structData *componentparser.StructData){
+		callMockery,
+		func(structData *componentparser.StructData) { extendMocks(session, structData) },
+		func(structData *componentparser.StructData) { generateSpy(session, structData) },
+	}
+	for _, step := range mockSteps {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		for _, structData := range result.Mocks {
+			step(structData)
+		}
+	}
+
+	/*
+		Each step is run for every component before moving on to the next, as
+		later steps depend on the output of earlier ones. The mocks can only be
+		created once all the interfaces exist.
+	*/
+	steps := []func(structData *componentparser.StructData){
+
+		// Create the interface files for each of the structs that were found
+		func(structData *componentparser.StructData) { generateInterface(session, structData) },
+
+		// Run the mockery command for each of the structs that were found
+		callMockery,
+
+		/*
+			Extend each of the mock files with additional functionality for
+			components. Simply adds some extra data to the end of each generated
+			mock file
+		*/
+		func(structData *componentparser.StructData) { extendMocks(session, structData) },
+
+		// Add a call recording spy beside each of the generated mocks
+		func(structData *componentparser.StructData) { generateSpy(session, structData) },
+
+		// Create test files for each of the structs
+		func(structData *componentparser.StructData) {
+			if structData.Options.SkipTestFile {
+				return
+			}
+			generateTest(session, structData)
+		},
+
+		// Run the plugins enabled for each of the structs last
+		func(structData *componentparser.StructData) { componentplugin.Run(session, structData) },
+	}
+
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		for _, structData := range result.Components {
+			step(structData)
+		}
+	}
+
+	return result, nil
+}
+
+// Read back every file written during the run
+func readGeneratedFiles(session *helpers.Session) ([]GeneratedFile, error) {
+	files := []GeneratedFile{}
+	for _, fileName := range session.GeneratedFiles() {
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, GeneratedFile{Path: fileName, Content: content})
+	}
+	return files, nil
+}
+
+// Convert a recovered panic value into an error
+func recoveredError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
@@ -0,0 +1,49 @@
+package generate
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/helpers"
+	"github.com/flywingedai/components/generate/templates"
+)
+
+/*
+Add a Benchmark$Component_$Method function to the benchmark file beside the
+test file for each method of the component which doesn't have one in any of the
+test files of the folder yet. The benchmark file is the test file with the
+_bench_test.go extension instead, and is created when it doesn't exist. Like
+the scaffolded tests, the benchmarks belong to the user once they are added.
+*/
+func scaffoldBenchmarks(
+	session *helpers.Session,
+	structData *componentparser.StructData,
+	t *templates.Templates,
+	data *templates.StructData,
+	testFileName string,
+) {
+	if len(structData.Generic) > 0 {
+		return
+	}
+
+	fileName := strings.TrimSuffix(testFileName, "_test.go") + "_bench_test.go"
+	code := scaffoldFunctions(structData, t, data, "Benchmark", "scaffoldBenchmark", path.Dir(fileName))
+	if code == "" {
+		return
+	}
+
+	// The file only needs the package clause before the code is added
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		err = os.WriteFile(fileName, []byte("package "+data.Package+"\n"), 0777)
+		if err != nil {
+			panic(err)
+		}
+	} else if err != nil {
+		panic(err)
+	}
+
+	imports := helpers.Imports{}.With(helpers.Import{Path: "testing"}, testsImport)
+	session.AppendToFile(fileName, code, imports)
+}
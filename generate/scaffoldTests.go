@@ -0,0 +1,95 @@
+package generate
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/helpers"
+	"github.com/flywingedai/components/generate/templates"
+)
+
+var errorsImport = helpers.Import{Path: "errors"}
+
+/*
+Add a Test$Component_$Method function to the end of the test file for each
+method of the component which doesn't have one in any of the test files of the
+folder yet. The functions are added outside of the generated regions, so once
+they exist they are never changed or overwritten. Test functions can't have
+type parameters, so nothing is scaffolded for generic components.
+*/
+func scaffoldTests(
+	session *helpers.Session,
+	structData *componentparser.StructData,
+	t *templates.Templates,
+	data *templates.StructData,
+	fileName string,
+) {
+	if len(structData.Generic) > 0 {
+		return
+	}
+
+	code := scaffoldFunctions(structData, t, data, "Test", "scaffoldTest", path.Dir(fileName))
+	if code == "" {
+		return
+	}
+
+	imports := helpers.Imports{}.With(helpers.Import{Path: "testing"}, testsImport, errorsImport)
+	session.AppendToFile(fileName, code, imports)
+}
+
+/*
+Execute the template for each method of the component which doesn't have a
+$prefix$Component_$Method function in any of the test files of the folder yet.
+*/
+func scaffoldFunctions(
+	structData *componentparser.StructData,
+	t *templates.Templates,
+	data *templates.StructData,
+	prefix string,
+	templateName string,
+	folder string,
+) string {
+	existing := testFunctions(folder)
+	code := ""
+	for _, method := range data.Methods {
+		name := prefix + helpers.ToTitle(structData.Name) + "_" + method.Name
+		if existing[name] {
+			continue
+		}
+		code += t.Execute(templateName, method)
+	}
+	return code
+}
+
+// The names of all the functions declared in the test files of the folder
+func testFunctions(folder string) map[string]bool {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		panic(err)
+	}
+
+	names := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path.Join(folder, entry.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			panic("could not parse " + path.Join(folder, entry.Name()) + ": " + err.Error())
+		}
+
+		for _, decl := range file.Decls {
+			if function, ok := decl.(*ast.FuncDecl); ok && function.Recv == nil {
+				names[function.Name.Name] = true
+			}
+		}
+	}
+	return names
+}
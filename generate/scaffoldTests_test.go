@@ -0,0 +1,107 @@
+package generate
+
+import (
+	"context"
+	"flag"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the generated tests")
+
+/*
+Generate the fixture in testdata/scaffold and compare the test files of its
+service component against the golden files next to it. The generated code has
+to pass go vet as well, which builds the fixture along with its tests.
+*/
+func TestScaffoldGolden(t *testing.T) {
+	for _, tool := range []string{"mockery", "goimports"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skip(tool + " is needed to generate the fixture")
+		}
+	}
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture := filepath.Join("testdata", "scaffold")
+	dir := t.TempDir()
+	copyFixture(t, fixture, dir)
+
+	goMod := "module example.com/fixture\n\ngo 1.21\n\n" +
+		"require (\n\tgithub.com/flywingedai/components v0.0.0\n\tgithub.com/stretchr/testify v1.8.4\n)\n\n" +
+		"replace github.com/flywingedai/components => " + root + "\n"
+	writeFixtureFile(t, filepath.Join(dir, "go.mod"), []byte(goMod))
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFixtureFile(t, filepath.Join(dir, "go.sum"), goSum)
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	_, err = Run(context.Background(), Config{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"service_test.go", "service_bench_test.go"} {
+		generated, err := os.ReadFile(filepath.Join(dir, "service", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		golden := filepath.Join(fixture, "service", name+".golden")
+		if *update {
+			writeFixtureFile(t, golden, generated)
+		}
+		expected, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, string(expected), string(generated), "%s doesn't match %s, run the test with -update if the change is expected", name, golden)
+	}
+
+	vet := exec.Command("go", "vet", "./...")
+	vet.Dir = dir
+	output, err := vet.CombinedOutput()
+	assert.NoError(t, err, "go vet failed on the generated fixture:\n%s", output)
+}
+
+// Copy the fixture into the directory, leaving out the golden files
+func copyFixture(t *testing.T, fixture, dir string) {
+	err := filepath.WalkDir(fixture, func(fileName string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(fileName) == ".golden" {
+			return err
+		}
+
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(fixture, fileName)
+		if err != nil {
+			return err
+		}
+		writeFixtureFile(t, filepath.Join(dir, relative), content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFixtureFile(t *testing.T, fileName string, content []byte) {
+	err := os.MkdirAll(filepath.Dir(fileName), 0777)
+	if err == nil {
+		err = os.WriteFile(fileName, content, 0666)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
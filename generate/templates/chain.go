@@ -1,65 +1,224 @@
 package templates
 
 const (
+
+	// Everything added to the end of the mockery mock. Executed with StructData.
+	ExtendMock = `{{template "expecterChain" .}}{{range .Methods}}{{template "chain" .}}{{end}}`
+
+	// Executed with StructData.
 	ExpecterChain = `
-type {{InterfaceName}}_ExpecterChain[M any{{GenericLongAppend}}] func(*M) *{{InterfaceName}}_Expecter{{GenericShort}}
-func Create_{{InterfaceName}}_ExpecterChain[M any{{GenericLongAppend}}](fetch func(*M) *{{InterfaceName}}{{GenericShort}}) {{InterfaceName}}_ExpecterChain[M{{GenericShortAppend}}] {
-	return func(m *M) *{{InterfaceName}}_Expecter{{GenericShort}} {
+type {{.InterfaceName}}_ExpecterChain[M any{{.Generic.LongAppend}}] func(*M) *{{.InterfaceName}}_Expecter{{.Generic.Short}}
+func Create_{{.InterfaceName}}_ExpecterChain[M any{{.Generic.LongAppend}}](fetch func(*M) *{{.InterfaceName}}{{.Generic.Short}}) {{.InterfaceName}}_ExpecterChain[M{{.Generic.ShortAppend}}] {
+	return func(m *M) *{{.InterfaceName}}_Expecter{{.Generic.Short}} {
 		c := fetch(m)
 		return c.EXPECT()
 	}
 }
+{{$i := .InterfaceName}}{{$g := .Generic}}
+/*
+Return values for the calls allowed by AllowAll(). Each method left nil returns
+zero values instead.
+*/
+type {{$i}}_Defaults{{$g.Long}} struct {
+{{- range .Methods}}
+	{{.Name}} func({{types .Args}}) {{results .Returns}}
+{{- end}}
+}
+
+type {{$i}}_AllowAllChain[M any{{$g.LongAppend}}] func(*M)
+
+/*
+Allow any call of every method, returning the defaults. The expectations are
+Maybe(), and every expectation registered afterwards by a chain takes
+precedence. Later defaults override earlier ones.
+*/
+func (_c {{$i}}_ExpecterChain[M{{$g.ShortAppend}}]) AllowAll(defaults ...{{$i}}_Defaults{{$g.Short}}) {{$i}}_AllowAllChain[M{{$g.ShortAppend}}] {
+	return func(m *M) {
+{{- if .Methods}}
+		expecter := _c(m)
+		table := {{$i}}_Defaults{{$g.Short}}{}
+		for _, d := range defaults {
+{{- range .Methods}}
+			if d.{{.Name}} != nil {
+				table.{{.Name}} = d.{{.Name}}
+			}
+{{- end}}
+		}
+{{range .Methods}}{{$rets := rename "r" .Returns}}
+		allow{{.Name}} := func(call *{{$i}}_{{.Name}}_Call{{$g.Short}}) {
+			call.Maybe()
+			if table.{{.Name}} != nil {
+				call.RunAndReturn(table.{{.Name}})
+			} else {
+{{- range $rets}}
+				var {{.Name}} {{.Type}}
+{{- end}}
+				call.Return({{names $rets}})
+			}
+			tests.Fallback(call.Call)
+		}
+{{- if variadic .Args}}
+		for count := 0; count <= tests.MaxAllowedVariadic; count++ {
+			allow{{.Name}}(expecter.{{.Name}}({{range fixed .Args}}mock.Anything, {{end}}tests.Anythings(count)...))
+		}
+{{- else}}
+		allow{{.Name}}(expecter.{{.Name}}({{range $n, $a := .Args}}{{if $n}}, {{end}}mock.Anything{{end}}))
+{{- end}}
+{{end}}
+{{- end}}
+	}
+}
 `
 
-	Chain = `
-type {{InterfaceName}}_{{Method}}Chain[M any{{GenericLongAppend}}] func(*M) *{{InterfaceName}}_{{Method}}_Call{{GenericShort}}
+	// Executed with each Method.
+	Chain = `{{$i := .Struct.InterfaceName}}{{$g := .Struct.Generic}}
+type {{$i}}_{{.Name}}Chain[M any{{$g.LongAppend}}] func(*M) *{{$i}}_{{.Name}}_Call{{$g.Short}}
 
-func (_c {{InterfaceName}}_ExpecterChain[M{{GenericShortAppend}}]) {{Method}}({{ArgsInterface}}) {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}] {
-	return func(m *M) *{{InterfaceName}}_{{Method}}_Call{{GenericShort}} {
+func (_c {{$i}}_ExpecterChain[M{{$g.ShortAppend}}]) {{.Name}}({{interfaces .Args}}) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
 		expecter := _c(m)
-		return expecter.{{Method}}({{ArgsShort}})
+		call := expecter.{{.Name}}({{spread .Args}})
+		tests.Prefer(call.Call)
+		return call
 	}
 }
 
-func (_c {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}]) Run(run func({{Args}})) {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}] {
-	return func(m *M) *{{InterfaceName}}_{{Method}}_Call{{GenericShort}} {
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) Run(run func({{args .Args}})) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
 		call := _c(m)
 		return call.Run(run)
 	}
 }
 
-func (_c {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}]) Return({{ReturnsArgs}}) {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}] {
-	return func(m *M) *{{InterfaceName}}_{{Method}}_Call{{GenericShort}} {
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) Return({{args .Returns}}) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
+		call := _c(m)
+		return call.Return({{names .Returns}})
+	}
+}
+
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) Once() {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.Once()
+		return call
+	}
+}
+
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) Twice() {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.Twice()
+		return call
+	}
+}
+
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) Times(n int) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.Times(n)
+		return call
+	}
+}
+
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) Maybe() {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
 		call := _c(m)
-		return call.Return({{ReturnsShort}})
+		call.Call.Maybe()
+		return call
 	}
 }
 
-func (_c {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}]) Once() {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}] {
-	return func(m *M) *{{InterfaceName}}_{{Method}}_Call{{GenericShort}} {
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) After(d time.Duration) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
 		call := _c(m)
-		return &{{InterfaceName}}_{{Method}}_Call{call.Once()}
+		call.Call.After(d)
+		return call
 	}
 }
 
-func (_c {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}]) RunAndReturn(run func({{Args}}){{ReturnsTypes}}) {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}] {
-	return func(m *M) *{{InterfaceName}}_{{Method}}_Call{{GenericShort}} {
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) WaitUntil(w <-chan time.Time) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.WaitUntil(w)
+		return call
+	}
+}
+
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) Panic(msg string) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.Panic(msg)
+		return call
+	}
+}
+
+/*
+Expect the call only after every one of the calls. The chains are set up along
+with this one, so they shouldn't also be passed to TestOptions.Mock().
+*/
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) NotBefore(chains ...tests.MockChain[M]) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
+		call := _c(m)
+		calls := []*mock.Call{}
+		for _, chain := range chains {
+			calls = append(calls, chain.MockCall(m))
+		}
+		call.Call.NotBefore(calls...)
+		return call
+	}
+}
+
+// Set up the expectation and return the testify call, see tests.MockChain
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) MockCall(m *M) *mock.Call {
+	return _c(m).Call
+}
+
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) RunAndReturn(run func({{args .Args}}) {{results .Returns}}) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
 		call := _c(m)
 		return call.RunAndReturn(run)
 	}
 }
 
-func (_c {{InterfaceName}}_ExpecterChain[M{{GenericShortAppend}}]) {{Method}}_P({{ArgsInterface}}) {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}] {
-	return func(m *M) *{{InterfaceName}}_{{Method}}_Call{{GenericShort}} {
+func (_c {{$i}}_ExpecterChain[M{{$g.ShortAppend}}]) {{.Name}}_P({{interfaces .Args}}) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
+		expecter := _c(m)
+		call := expecter.{{.Name}}({{range $n, $a := fixed .Args}}{{if $n}}, {{end}}tests.RemoveInterfacePointer[{{$a.Type}}]({{$a.Name}}){{end}}
+		{{- with variadic .Args}}{{if fixed $.Args}}, {{end}}tests.RemoveInterfacePointers[{{elem .Type}}]({{.Name}})...{{end}})
+		tests.Prefer(call.Call)
+		return call
+	}
+}
+
+func (_c {{$i}}_ExpecterChain[M{{$g.ShortAppend}}]) {{.Name}}_T({{args .Args}}) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
+		expecter := _c(m)
+		call := expecter.{{.Name}}({{names (fixed .Args)}}
+		{{- with variadic .Args}}{{if fixed $.Args}}, {{end}}tests.VariadicArgs({{.Name}})...{{end}})
+		tests.Prefer(call.Call)
+		return call
+	}
+}
+
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) Return_T({{args .Returns}}) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return _c.Return({{names .Returns}})
+}
+
+func (_c {{$i}}_ExpecterChain[M{{$g.ShortAppend}}]) {{.Name}}_M({{range $n, $a := .Args}}{{if $n}}, {{end}}{{$a.Name}} {{variadicPrefix $a.Type}}tests.Matcher[{{elem $a.Type}}]{{end}}) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
 		expecter := _c(m)
-		return expecter.{{Method}}({{ArgsShortPointer}})
+		call := expecter.{{.Name}}({{range $n, $a := fixed .Args}}{{if $n}}, {{end}}tests.MatchArg({{$a.Name}}){{end}}
+		{{- with variadic .Args}}{{if fixed $.Args}}, {{end}}tests.MatchArgs({{.Name}})...{{end}})
+		tests.Prefer(call.Call)
+		return call
 	}
 }
 
-func (_c {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}]) Return_P({{ReturnsArgsPointer}}) {{InterfaceName}}_{{Method}}Chain[M{{GenericShortAppend}}] {
-	return func(m *M) *{{InterfaceName}}_{{Method}}_Call{{GenericShort}} {
+func (_c {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}]) Return_P({{pointers .Returns}}) {{$i}}_{{.Name}}Chain[M{{$g.ShortAppend}}] {
+	return func(m *M) *{{$i}}_{{.Name}}_Call{{$g.Short}} {
 		call := _c(m)
-		return call.Return({{ReturnsShortPointer}})
+		return call.Return({{derefs .Returns}})
 	}
 }
 `
@@ -0,0 +1,259 @@
+package templates
+
+import (
+	"strings"
+
+	"github.com/flywingedai/components/generate/componentparser"
+)
+
+/*
+The data every component template is executed with. Type strings are already
+qualified for the package of the file being generated, so a type from the
+component's package reads "service.User" inside of a mock file but just "User"
+inside of the component's own package.
+*/
+type StructData struct {
+	Name        string // Name of the component struct
+	PackageName string // Name of the package the component lives in
+	PackagePath string // Import path of the package the component lives in
+
+	InterfaceName    string // Name of the generated interface
+	InterfacePackage string // Name of the package of the generated interface
+	InterfacePath    string // Import path of the package of the generated interface
+	MockPackage      string // Name of the package of the generated mocks
+
+	/*
+		The package the generated file belongs to, along with the prefixes
+		needed to reference the component package and the interface package
+		from that file. The prefixes are "" when the packages are the same.
+	*/
+	Package         string
+	ComponentPrefix string
+	InterfacePrefix string
+
+	Generic Generic   // Type parameters of the component
+	Fields  []*Field  // Fields of the component struct
+	Methods []*Method // Exported methods of the component
+
+	/*
+		The receiver variable and body of Params.Convert(). Only set for the
+		test templates, where the body builds the mocks struct instead.
+	*/
+	ConvertVar  string
+	ConvertBody string
+
+	/*
+		A zero value of the concrete type an interface was extracted from,
+		such as "(*storage.Client)(nil)". Only set for the extract template.
+	*/
+	ConcreteValue string
+
+	// Everything the parser found, for anything not covered above
+	Component *componentparser.StructData
+}
+
+/*
+Type parameters formatted for use in generated code. For a component with
+[K comparable, V any]:
+  - Short: "[K, V]"
+  - Long: "[K comparable, V any]"
+  - ShortAppend: ", K, V"
+  - LongAppend: ", K comparable, V any"
+
+All of them are "" when there are no type parameters.
+*/
+type Generic struct {
+	Short       string
+	Long        string
+	ShortAppend string
+	LongAppend  string
+}
+
+// An exported method of a component
+type Method struct {
+	Name    string
+	Args    []*Field
+	Returns []*Field // Unnamed returns are named _a0..._aN
+
+	/*
+		The mocked fields with an expecter which are used directly by the body
+		of the method, in the order they are first used.
+	*/
+	Mocks []*Field
+
+	// The component the method belongs to
+	Struct *StructData
+}
+
+/*
+A named value with a type. Used for the fields of the component struct as well
+as for the args and returns of its methods.
+*/
+type Field struct {
+	Name string
+	Type string
+
+	// The mock of the field, nil if the field is not mocked
+	Mock *Mock
+
+	// The function mock of the field, nil if the field is not mocked:"func"
+	Func *Func
+
+	// Whether or not a mock_$Field() expecter is generated for the field
+	Expecter bool
+
+	// The component the field belongs to. Only set for the component fields.
+	Struct *StructData
+}
+
+// How a mocked field is mocked, from the pkg, new and type tags
+type Mock struct {
+	Package string  // Name of the mock package
+	New     string  // Function creating the mock
+	Type    string  // Type of the mock, including any type arguments
+	Name    string  // Type of the mock without type arguments
+	Generic Generic // Type arguments of the mock
+
+	/*
+		Whether the spy beside the mock is used instead, from mock:"spy". Type
+		and New are then those of the spy, whose constructor takes no args.
+	*/
+	Spy bool
+}
+
+// How a function typed field is mocked, from the params and results of its type
+type Func struct {
+	Args    []*Field
+	Returns []*Field
+}
+
+/*
+Build the template data for a component. The package is the name of the
+package the generated file belongs to.
+*/
+func NewStructData(structData *componentparser.StructData, packageName string) *StructData {
+	data := &StructData{
+		Name:        structData.Name,
+		PackageName: structData.PackageName,
+		PackagePath: structData.PackagePath,
+
+		InterfaceName:    structData.Options.InterfaceName,
+		InterfacePackage: structData.Options.InterfacePackage,
+		InterfacePath:    structData.Options.InterfacePath,
+		MockPackage:      structData.Options.MockPackage,
+
+		Package:         packageName,
+		ComponentPrefix: prefix(structData.PackageName, packageName),
+		InterfacePrefix: prefix(structData.Options.InterfacePackage, packageName),
+
+		Generic: NewGeneric(structData.Generic),
+		Fields:  []*Field{},
+		Methods: []*Method{},
+
+		Component: structData,
+	}
+
+	// Types local to the component package must be qualified anywhere else
+	qualify := func(t string) string {
+		if packageName == structData.PackageName {
+			return t
+		}
+		return structData.QualifyType(t)
+	}
+	fields := func(parsed componentparser.Fields) []*Field {
+		converted := []*Field{}
+		for _, f := range parsed {
+			converted = append(converted, &Field{Name: f.Name, Type: qualify(f.Type)})
+		}
+		return converted
+	}
+
+	for _, f := range structData.Fields {
+		field := &Field{Name: f.Name, Type: qualify(f.Type), Struct: data}
+
+		if f.MockFunc {
+			args, returns := componentparser.FuncFields(field.Type)
+			field.Func = &Func{Args: fields(args), Returns: fields(returns)}
+		}
+
+		if f.MockPkg != "" || f.MockFunc {
+			if f.MockPkg != "" {
+				field.Mock = &Mock{
+					Package: f.MockPkg,
+					New:     f.MockNew,
+					Type:    f.MockType,
+					Name:    f.MockType,
+				}
+
+				// Split any type arguments off of the mock type
+				startIndex, endIndex := strings.Index(f.MockType, "["), strings.Index(f.MockType, "]")
+				if startIndex != -1 && endIndex != -1 {
+					field.Mock.Name = f.MockType[:startIndex]
+					field.Mock.Generic = NewGeneric(componentparser.ConvertTypeString(f.MockType[startIndex+1 : endIndex]))
+				}
+
+				if f.MockSpy {
+					field.Mock.Spy = true
+					field.Mock.Type = field.Mock.Name + "Spy" + field.Mock.Generic.Short
+					field.Mock.New = "New" + field.Mock.Name + "Spy" + field.Mock.Generic.Short
+				}
+			}
+
+			// Without any expecters listed, every mocked field gets one
+			field.Expecter = len(structData.Options.Expecters) == 0
+			for _, expecter := range structData.Options.Expecters {
+				if expecter == f.Name {
+					field.Expecter = true
+				}
+			}
+
+			// Spies don't have expectations to set up
+			if f.MockSpy {
+				field.Expecter = false
+			}
+		}
+
+		data.Fields = append(data.Fields, field)
+	}
+
+	expecters := map[string]*Field{}
+	for _, f := range data.Fields {
+		if f.Expecter {
+			expecters[f.Name] = f
+		}
+	}
+
+	for _, m := range structData.Methods {
+		method := &Method{
+			Name:    m.Name,
+			Args:    fields(m.Args),
+			Returns: fields(m.Returns),
+			Mocks:   []*Field{},
+			Struct:  data,
+		}
+		for _, use := range m.Uses {
+			if f, ok := expecters[use]; ok {
+				method.Mocks = append(method.Mocks, f)
+			}
+		}
+		data.Methods = append(data.Methods, method)
+	}
+
+	return data
+}
+
+// Format the type parameters of a component or mock
+func NewGeneric(fields componentparser.Fields) Generic {
+	generic := Generic{}
+	generic.Short, generic.Long = fields.Generic(false)
+	generic.ShortAppend, generic.LongAppend = fields.Generic(true)
+	return generic
+}
+
+// The prefix needed to reference a package from inside of another
+func prefix(packageName, fromPackage string) string {
+	if packageName == fromPackage {
+		return ""
+	}
+	return packageName + "."
+}
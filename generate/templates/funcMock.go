@@ -0,0 +1,262 @@
+package templates
+
+const (
+
+	/*
+		The mock of a function typed field, called through its Func method.
+		Executed with each Field with a Func.
+	*/
+	FuncMock = `{{$n := .Name}}{{$g := .Struct.Generic}}{{$args := rename "a" .Func.Args}}{{$rets := rename "r" .Func.Returns}}
+type {{$n}}_FuncMock{{$g.Long}} struct {
+	mock.Mock
+}
+
+func new_{{$n}}_FuncMock{{$g.Long}}(t testing.TB) *{{$n}}_FuncMock{{$g.Short}} {
+	m := &{{$n}}_FuncMock{{$g.Short}}{}
+	m.Mock.Test(t)
+	t.Cleanup(func() { m.AssertExpectations(t) })
+	return m
+}
+
+func (_m *{{$n}}_FuncMock{{$g.Short}}) Func({{args $args}}) {{results $rets}} {
+	_ca := []interface{}{ {{names (fixed $args)}} }
+{{- with variadic $args}}
+	_ca = append(_ca, tests.VariadicArgs({{.Name}})...)
+{{- end}}
+	ret := _m.Called(_ca...)
+
+	if len(ret) > 0 {
+		if run, ok := ret.Get(0).(func({{types $args}}) {{results $rets}}); ok {
+			{{if $rets}}return {{end}}run({{spread $args}})
+			{{- if not $rets}}
+			return
+			{{- end}}
+		}
+	}
+{{range $i, $r := $rets}}
+	var {{$r.Name}} {{$r.Type}}
+	if len(ret) > {{$i}} && ret.Get({{$i}}) != nil {
+		{{$r.Name}} = ret.Get({{$i}}).({{$r.Type}})
+	}
+{{end}}
+{{- if $rets}}
+	return {{names $rets}}
+{{- end}}
+}
+
+type {{$n}}_FuncMock_Call{{$g.Long}} struct {
+	*mock.Call
+}
+
+func (_c *{{$n}}_FuncMock_Call{{$g.Short}}) Run(run func({{args $args}})) *{{$n}}_FuncMock_Call{{$g.Short}} {
+	_c.Call.Run(func(args mock.Arguments) {
+{{- range $i, $a := fixed $args}}
+		var {{$a.Name}} {{$a.Type}}
+		if args[{{$i}}] != nil {
+			{{$a.Name}} = args[{{$i}}].({{$a.Type}})
+		}
+{{- end}}
+{{- with variadic $args}}
+		{{.Name}} := make({{slice .Type}}, len(args)-{{len (fixed $args)}})
+		for _i, _va := range args[{{len (fixed $args)}}:] {
+			if _va != nil {
+				{{.Name}}[_i] = _va.({{elem .Type}})
+			}
+		}
+{{- end}}
+		run({{spread $args}})
+	})
+	return _c
+}
+
+func (_c *{{$n}}_FuncMock_Call{{$g.Short}}) Return({{args $rets}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+	_c.Call.Return({{names $rets}})
+	return _c
+}
+
+func (_c *{{$n}}_FuncMock_Call{{$g.Short}}) RunAndReturn(run func({{types $args}}) {{results $rets}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_c *{{$n}}_FuncMock_Call{{$g.Short}}) Once() *{{$n}}_FuncMock_Call{{$g.Short}} {
+	_c.Call.Once()
+	return _c
+}
+`
+
+	/*
+		The mock_$Field() binding of a function mock, usable with
+		TestOptions.Mock. Executed with each Field with a Func and an expecter.
+	*/
+	FuncMockField = `{{$n := .Name}}{{$g := .Struct.Generic}}{{$args := rename "a" .Func.Args}}{{$rets := rename "r" .Func.Returns}}
+type {{$n}}_FuncExpecterChain{{$g.Long}} func(*mocks{{$g.Short}}) *{{$n}}_FuncMock{{$g.Short}}
+
+func mock_{{$n}}{{$g.Long}}() {{$n}}_FuncExpecterChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock{{$g.Short}} {
+		return m.{{$n}}
+	}
+}
+
+type {{$n}}_FuncChain{{$g.Long}} func(*mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}}
+
+func (_c {{$n}}_FuncExpecterChain{{$g.Short}}) Call({{interfaces $args}}) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		funcMock := _c(m)
+{{- with variadic $args}}
+		call := funcMock.On("Func", append([]interface{}{ {{names (fixed $args)}} }, {{.Name}}...)...)
+{{- else}}
+		call := funcMock.On("Func"{{range $args}}, {{.Name}}{{end}})
+{{- end}}
+		tests.Prefer(call)
+		return &{{$n}}_FuncMock_Call{{$g.Short}}{Call: call}
+	}
+}
+
+func (_c {{$n}}_FuncExpecterChain{{$g.Short}}) Call_T({{args $args}}) {{$n}}_FuncChain{{$g.Short}} {
+	return _c.Call({{names (fixed $args)}}{{with variadic $args}}{{if fixed $args}}, {{end}}tests.VariadicArgs({{.Name}})...{{end}})
+}
+
+func (_c {{$n}}_FuncExpecterChain{{$g.Short}}) Call_M({{range $i, $a := $args}}{{if $i}}, {{end}}{{$a.Name}} {{variadicPrefix $a.Type}}tests.Matcher[{{elem $a.Type}}]{{end}}) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		funcMock := _c(m)
+		matchers := []interface{}{ {{- range $i, $a := fixed $args}}{{if $i}}, {{end}}tests.MatchArg({{$a.Name}}){{end}}}
+{{- with variadic $args}}
+		matchers = append(matchers, tests.MatchArgs({{.Name}})...)
+{{- end}}
+		call := funcMock.On("Func", matchers...)
+		tests.Prefer(call)
+		return &{{$n}}_FuncMock_Call{{$g.Short}}{Call: call}
+	}
+}
+
+type {{$n}}_FuncAllowAllChain{{$g.Long}} func(*mocks{{$g.Short}})
+
+/*
+Allow any call of the function, returning zero values or the result of the
+last of the runs. The expectations are Maybe(), and every expectation
+registered afterwards by a chain takes precedence.
+*/
+func (_c {{$n}}_FuncExpecterChain{{$g.Short}}) AllowAll(runs ...func({{types $args}}) {{results $rets}}) {{$n}}_FuncAllowAllChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) {
+		funcMock := _c(m)
+		allow := func(call *mock.Call) {
+			call.Maybe()
+			if len(runs) > 0 {
+				call.Return(runs[len(runs)-1])
+			}
+			tests.Fallback(call)
+		}
+{{- if variadic $args}}
+		for count := 0; count <= tests.MaxAllowedVariadic; count++ {
+			allow(funcMock.On("Func", tests.Anythings({{len (fixed $args)}}+count)...))
+		}
+{{- else}}
+		allow(funcMock.On("Func", tests.Anythings({{len $args}})...))
+{{- end}}
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) Run(run func({{args $args}})) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		return call.Run(run)
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) Return({{args $rets}}) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		return call.Return({{names $rets}})
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) Return_T({{args $rets}}) {{$n}}_FuncChain{{$g.Short}} {
+	return _c.Return({{names $rets}})
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) RunAndReturn(run func({{types $args}}) {{results $rets}}) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		return call.RunAndReturn(run)
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) Once() {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.Once()
+		return call
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) Twice() {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.Twice()
+		return call
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) Times(n int) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.Times(n)
+		return call
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) Maybe() {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.Maybe()
+		return call
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) After(d time.Duration) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.After(d)
+		return call
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) WaitUntil(w <-chan time.Time) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.WaitUntil(w)
+		return call
+	}
+}
+
+func (_c {{$n}}_FuncChain{{$g.Short}}) Panic(msg string) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		call.Call.Panic(msg)
+		return call
+	}
+}
+
+/*
+Expect the call only after every one of the calls. The chains are set up along
+with this one, so they shouldn't also be passed to TestOptions.Mock().
+*/
+func (_c {{$n}}_FuncChain{{$g.Short}}) NotBefore(chains ...tests.MockChain[mocks{{$g.Short}}]) {{$n}}_FuncChain{{$g.Short}} {
+	return func(m *mocks{{$g.Short}}) *{{$n}}_FuncMock_Call{{$g.Short}} {
+		call := _c(m)
+		calls := []*mock.Call{}
+		for _, chain := range chains {
+			calls = append(calls, chain.MockCall(m))
+		}
+		call.Call.NotBefore(calls...)
+		return call
+	}
+}
+
+// Set up the expectation and return the testify call, see tests.MockChain
+func (_c {{$n}}_FuncChain{{$g.Short}}) MockCall(m *mocks{{$g.Short}}) *mock.Call {
+	return _c(m).Call
+}
+`
+)
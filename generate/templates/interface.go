@@ -1,15 +1,26 @@
 package templates
 
+// The generated interface of a component. Executed with StructData.
 const Interface = `
-type %s%s interface {
-%s
+type {{.InterfaceName}}{{.Generic.Long}} interface {
+{{- range .Methods}}
+	{{.Name}}({{args .Args}}) {{results .Returns}}
+{{- end}}
 }
 `
 
-const Method = "\t%s(%s) %s"
-
+// The New function added to the component's file. Executed with StructData.
 const New = `
-func New{{GenericLong}}(p Params{{GenericShort}}) {{Interface}}{{GenericShort}} {
+func New{{.Generic.Long}}(p {{.ComponentPrefix}}Params{{.Generic.Short}}) {{.InterfacePrefix}}{{.InterfaceName}}{{.Generic.Short}} {
 	return p.Convert()
 }
 `
+
+/*
+An interface extracted from the concrete type of a component field, followed by
+an assertion that the concrete type still implements it. Executed with the
+StructData of the extracted interface.
+*/
+const Extract = `{{template "interface" .}}
+var _ {{.InterfaceName}} = {{.ConcreteValue}}
+`
@@ -1,26 +1,213 @@
 package templates
 
 const (
+
+	/*
+		Added to the top of a new test file, above the generated code, so it can
+		be edited freely. Executed with StructData.
+	*/
 	InitParams = `
-func initParams{{GenericLong}}() {{ParamsPath}}{{GenericShort}} {
-	return {{ParamsPath}}{{GenericShort}}{}
+func initParams{{.Generic.Long}}() {{.ComponentPrefix}}Params{{.Generic.Short}} {
+	return {{.ComponentPrefix}}Params{{.Generic.Short}}{}
 }
 `
 
+	// Everything generated in the test file. Executed with StructData.
+	Test = `{{template "mocks" .}}{{range .Fields}}{{if .Func}}{{template "funcMock" .}}{{end}}{{end}}
+{{template "convert" .}}{{template "buildMocks" .}}
+{{- range .Fields}}{{if .Expecter}}{{if .Func}}{{template "funcMockField" .}}{{else}}{{template "mockField" .}}{{end}}{{else if and .Mock .Mock.Spy}}{{template "spyField" .}}{{end}}{{end}}
+{{- range .Methods}}{{template "methodHandle" .}}{{template "methodTest" .}}{{end}}
+{{- if .Component.Options.ScaffoldBenchmarks}}{{template "buildBenchMocks" .}}{{end}}`
+
+	// Executed with StructData.
+	Mocks = `type mocks{{.Generic.Long}} struct{
+{{- range .Fields}}
+	{{if .Mock}}{{.Name}} *{{.Mock.Package}}.{{.Mock.Type}}{{else if .Func}}{{.Name}} *{{.Name}}_FuncMock{{.Struct.Generic.Short}}{{else}}{{.Name}} {{.Type}}{{end}}
+{{- end}}
+}
+
+`
+
+	// Executed with StructData.
+	Convert = `func convert{{.Generic.Long}}({{.ConvertVar}} {{.ComponentPrefix}}Params{{.Generic.Short}}) *mocks{{.Generic.Short}} {{.ConvertBody}}
+
+`
+
+	/*
+		Builds the component and its mocks for any testing.TB, behind the
+		buildMocks() of the tests and the buildBenchMocks() of the benchmarks.
+		Executed with StructData.
+	*/
 	BuildMocks = `
-func buildMocks{{GenericLong}}(t *testing.T) ({{InterfacePackage}}{{InterfaceName}}{{GenericShort}}, *mocks{{GenericShort}}) {
-	params := initParams{{GenericShort}}()
+func buildMocksTB{{.Generic.Long}}(tb testing.TB) ({{.InterfacePrefix}}{{.InterfaceName}}{{.Generic.Short}}, *mocks{{.Generic.Short}}) {
+	params := initParams{{.Generic.Short}}()
+
+	{{range .Fields}}{{if .Mock}}params.{{title .Name}} = {{.Mock.Package}}.{{.Mock.New}}({{if not .Mock.Spy}}tb{{end}})
+{{else if .Func}}{{.Name}}_func := new_{{.Name}}_FuncMock{{$.Generic.Short}}(tb)
+	params.{{title .Name}} = {{.Name}}_func.Func
+{{end}}{{end}}
+
+	converted := convert(params)
+	{{range .Fields}}{{if .Func}}converted.{{.Name}} = {{.Name}}_func
+{{end}}{{end}}
+
+	return {{.ComponentPrefix}}New(params), converted
+}
+
+func buildMocks{{.Generic.Long}}(t *testing.T) ({{.InterfacePrefix}}{{.InterfaceName}}{{.Generic.Short}}, *mocks{{.Generic.Short}}) {
+	return buildMocksTB{{.Generic.Short}}(t)
+}
+`
+
+	/*
+		buildMocks() for benchmarks, only generated for components with
+		scaffoldBenchmarks::true. Executed with StructData.
+	*/
+	BuildBenchMocks = `
+func buildBenchMocks{{.Generic.Long}}(b *testing.B) ({{.InterfacePrefix}}{{.InterfaceName}}{{.Generic.Short}}, *mocks{{.Generic.Short}}) {
+	return buildMocksTB{{.Generic.Short}}(b)
+}
+`
+
+	/*
+		A typed handle to a method of the component, for the method tests of
+		the tester. Executed with each Method.
+	*/
+	MethodHandle = `{{$s := .Struct}}
+func method_{{.Name}}{{$s.Generic.Long}}() tests.Method[{{$s.InterfacePrefix}}{{$s.InterfaceName}}{{$s.Generic.Short}}] {
+	return tests.NewMethod("{{.Name}}", func(c {{$s.InterfacePrefix}}{{$s.InterfaceName}}{{$s.Generic.Short}}) interface{} {
+		return c.{{.Name}}
+	})
+}
+`
+
+	/*
+		The typed args and returns of a method of the component, along with
+		the test_$Method() builder which turns them into the inputs and outputs
+		of a method test. Executed with each Method.
+	*/
+	MethodTest = `{{$s := .Struct}}{{$g := $s.Generic}}{{$args := exported "Arg" .Args}}{{$rets := exported "Ret" .Returns}}
+{{- $c := print $s.InterfacePrefix $s.InterfaceName $g.Short}}{{$t := print .Name "Test" $g.Short}}
+type {{.Name}}Args{{$g.Long}} struct {
+{{- range $args}}
+	{{.Name}} {{slice .Type}}
+{{- end}}
+}
 
-	{{MockFields}}
+type {{.Name}}Returns{{$g.Long}} struct {
+{{- range $rets}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+
+type {{.Name}}Test{{$g.Long}} struct {
+	test tests.TypedTest[{{$c}}]
+}
+
+func test_{{.Name}}{{$g.Long}}() {{$t}} {
+	return {{$t}}{test: tests.TypedTest[{{$c}}]{Method: method_{{.Name}}{{$g.Short}}(), Inputs: []interface{}{}}}
+}
+
+func (_t {{$t}}) Args(args {{.Name}}Args{{$g.Short}}) {{$t}} {
+	_t.test.Inputs = []interface{}{ {{- range $n, $a := fixed $args}}{{if $n}}, {{end}}args.{{$a.Name}}{{end}}}
+{{- with variadic $args}}
+	_t.test.Inputs = append(_t.test.Inputs, tests.VariadicArgs(args.{{.Name}})...)
+{{- end}}
+	return _t
+}
+
+func (_t {{$t}}) Returns(returns {{.Name}}Returns{{$g.Short}}) {{$t}} {
+	_t.test.Outputs = []interface{}{ {{- range $n, $r := $rets}}{{if $n}}, {{end}}returns.{{$r.Name}}{{end}}}
+	return _t
+}
+
+func (_t {{$t}}) TypedTest() tests.TypedTest[{{$c}}] {
+	return _t.test
+}
+`
+
+	/*
+		A Test$Component_$Method function with a success case, and an error
+		case when the method returns an error, for components with
+		scaffoldTests::true. The error case only allows the calls to the
+		mocks, making one of them fail is left to the user.
+		Executed with each Method of a component without type parameters.
+	*/
+	ScaffoldTest = `{{$fail := ""}}{{range exported "Ret" .Returns}}{{if eq .Type "error"}}{{$fail = .Name}}{{end}}{{end}}
+func Test{{title .Struct.Name}}_{{.Name}}(t *testing.T) {
+	t.Skip("TODO: fill in the scaffolded cases of {{.Name}} and remove this skip")
+
+	tester := tests.NewTesterWithoutInit(buildMocks)
+{{- if $fail}}
+	failed := errors.New("failed")
+{{- end}}
+
+	tester.NewOptions().
+{{- range .Mocks}}
+		Mock(mock_{{.Name}}().AllowAll()).
+{{- end}}
+		RegisterTypedTest(test_{{.Name}}().
+			Args({{.Name}}Args{}).
+			Returns({{.Name}}Returns{}), "success")
+{{- if $fail}}
+
+	// TODO: make one of the mocked calls of {{.Name}} return an error
+	tester.NewOptions().
+{{- range .Mocks}}
+		Mock(mock_{{.Name}}().AllowAll()).
+{{- end}}
+		RegisterTypedTest(test_{{.Name}}().
+			Args({{.Name}}Args{}).
+			Returns({{.Name}}Returns{ {{- $fail}}: failed}), "error")
+{{- end}}
+
+	tester.Test(t)
+}
+`
+
+	/*
+		A Benchmark$Component_$Method function, for components with
+		scaffoldBenchmarks::true. The mocks the method uses allow every call
+		before the timer starts, and the benchmark skips itself until its args
+		are filled in. Executed with each Method of a component without type
+		parameters.
+	*/
+	ScaffoldBenchmark = `{{$args := exported "Arg" .Args}}
+func Benchmark{{title .Struct.Name}}_{{.Name}}(b *testing.B) {
+	b.Skip("TODO: fill in the scaffolded args of {{.Name}} and remove this skip")
+
+	c, {{if .Mocks}}m{{else}}_{{end}} := buildBenchMocks(b)
+{{- range .Mocks}}
+	mock_{{.Name}}().AllowAll()(m)
+{{- end}}
+{{- if .Args}}
+	args := {{.Name}}Args{}
+{{- end}}
+
+	tests.Bench(b, func() {
+		c.{{.Name}}({{range $n, $a := fixed $args}}{{if $n}}, {{end}}args.{{$a.Name}}{{end}}
+		{{- with variadic $args}}{{if fixed $args}}, {{end}}args.{{.Name}}...{{end}})
+	})
+}
+`
 
-	return {{ComponentPackage}}New(params), convert(params)
+	/*
+		The spy_$Field() binding of a mock:"spy" field, for the spy assertions
+		of TestOptions. Executed with each Field holding a spy.
+	*/
+	GetSpyField = `{{$g := .Struct.Generic}}
+func spy_{{.Name}}{{$g.Long}}() func(m *mocks{{$g.Short}}) tests.SpyLog {
+	return func(m *mocks{{$g.Short}}) tests.SpyLog {
+		return m.{{.Name}}.SpyLog()
+	}
 }
 `
 
-	GetMockField = `
-func mock_{{FieldName}}() {{MockPackage}}.{{MockType}}_ExpecterChain[mocks{{GenericShortAppend}}] {
-	return {{MockPackage}}.Create_{{MockType}}_ExpecterChain(func(m *mocks) *{{MockPackage}}.{{MockType}}{{GenericShort}} {
-		return m.{{FieldName}}
+	// Executed with each mocked Field that has an expecter.
+	GetMockField = `{{$g := .Struct.Generic}}
+func mock_{{.Name}}{{$g.Long}}() {{.Mock.Package}}.{{.Mock.Name}}_ExpecterChain[mocks{{$g.Short}}{{.Mock.Generic.ShortAppend}}] {
+	return {{.Mock.Package}}.Create_{{.Mock.Name}}_ExpecterChain(func(m *mocks{{$g.Short}}) *{{.Mock.Package}}.{{.Mock.Name}}{{.Mock.Generic.Short}} {
+		return m.{{.Name}}
 	})
 }
 `
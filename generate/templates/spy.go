@@ -0,0 +1,53 @@
+package templates
+
+const (
+
+	/*
+		The log is kept in an unexported field behind SpyLog(), so none of its
+		methods can clash with the methods of the interface. Executed with
+		StructData.
+	*/
+	Spy = `
+type {{.InterfaceName}}Spy{{.Generic.Long}} struct {
+	log tests.Spy
+}
+
+func New{{.InterfaceName}}Spy{{.Generic.Long}}() *{{.InterfaceName}}Spy{{.Generic.Short}} {
+	return &{{.InterfaceName}}Spy{{.Generic.Short}}{}
+}
+
+// The calls recorded by the spy and the handlers configured for its methods
+func (_s *{{.InterfaceName}}Spy{{.Generic.Short}}) SpyLog() *tests.Spy {
+	return &_s.log
+}
+{{range .Methods}}{{template "spyMethod" .}}{{end}}`
+
+	/*
+		Executed with each Method. The args are renamed to a0...aN so they can't
+		clash with the locals of the generated method, and the returns to
+		r0...rN so they can be declared as local variables inside of it.
+	*/
+	SpyMethod = `{{$spy := print .Struct.InterfaceName "Spy" .Struct.Generic.Short}}{{$args := rename "a" .Args}}{{$returns := rename "r" .Returns}}
+func (_s *{{$spy}}) {{.Name}}({{args $args}}) {{results $returns}} {
+{{- range $returns}}
+	var {{.Name}} {{.Type}}
+{{- end}}
+	if handler, ok := _s.log.Handler("{{.Name}}").(func({{types .Args}}) {{results $returns}}); ok {
+		{{if $returns}}{{names $returns}} = {{end}}handler({{spread $args}})
+	}
+	_s.log.Record("{{.Name}}", []interface{}{ {{names $args}} }, []interface{}{ {{names $returns}} })
+	return {{names $returns}}
+}
+
+func (_s *{{$spy}}) {{.Name}}_Returns({{args $returns}}) *{{$spy}} {
+	return _s.{{.Name}}_Func(func({{types .Args}}) {{results $returns}} {
+		return {{names $returns}}
+	})
+}
+
+func (_s *{{$spy}}) {{.Name}}_Func(handler func({{types .Args}}) {{results $returns}}) *{{$spy}} {
+	_s.log.SetHandler("{{.Name}}", handler)
+	return _s
+}
+`
+)
@@ -0,0 +1,282 @@
+package templates
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/flywingedai/components/generate/helpers"
+)
+
+// Extension of the files in a templates directory which override a built-in
+const Extension = ".tmpl"
+
+/*
+Every built-in template keyed by its name. A file named $name.tmpl in a
+templates directory replaces the template with the same name.
+*/
+var builtin = map[string]string{
+	"interface": Interface,
+	"new":       New,
+	"extract":   Extract,
+
+	"extendMock":    ExtendMock,
+	"expecterChain": ExpecterChain,
+	"chain":         Chain,
+
+	"spy":       Spy,
+	"spyMethod": SpyMethod,
+
+	"test":       Test,
+	"initParams": InitParams,
+	"mocks":      Mocks,
+	"convert":    Convert,
+	"buildMocks": BuildMocks,
+	"mockField":  GetMockField,
+	"spyField":   GetSpyField,
+
+	"methodHandle": MethodHandle,
+	"methodTest":   MethodTest,
+	"scaffoldTest": ScaffoldTest,
+
+	"buildBenchMocks":   BuildBenchMocks,
+	"scaffoldBenchmark": ScaffoldBenchmark,
+
+	"funcMock":      FuncMock,
+	"funcMockField": FuncMockField,
+
+	"wire":     Wire,
+	"wireStep": WireStep,
+}
+
+// The full set of templates used for a single generation
+type Templates struct {
+	set *template.Template
+}
+
+/*
+Parse the built-in templates along with any overrides found in the directory.
+An empty directory uses only the built-in templates. Panics if an override does
+not match the name of a built-in template or fails to parse.
+*/
+func Load(dir string) *Templates {
+	set := template.New("components").Funcs(Funcs)
+
+	names := []string{}
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		template.Must(set.New(name).Parse(builtin[name]))
+	}
+
+	if dir == "" {
+		return &Templates{set: set}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != Extension {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), Extension)
+		if _, ok := builtin[name]; !ok {
+			panic("template " + filepath.Join(dir, entry.Name()) + " does not override any of the built-in templates: " + strings.Join(names, ", "))
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		_, err = set.New(name).Parse(string(content))
+		if err != nil {
+			panic("could not parse template " + filepath.Join(dir, entry.Name()) + ": " + err.Error())
+		}
+	}
+
+	return &Templates{set: set}
+}
+
+// Execute the named template. Panics if the template fails.
+func (t *Templates) Execute(name string, data interface{}) string {
+	buffer := &bytes.Buffer{}
+	err := t.set.ExecuteTemplate(buffer, name, data)
+	if err != nil {
+		panic("could not execute template " + name + ": " + err.Error())
+	}
+	return buffer.String()
+}
+
+/////////////
+// HELPERS //
+/////////////
+
+// Functions available inside of every template
+var Funcs = template.FuncMap{
+	"args":           joinFields(func(f *Field) string { return f.Name + " " + f.Type }),
+	"types":          joinFields(func(f *Field) string { return f.Type }),
+	"names":          joinFields(func(f *Field) string { return f.Name }),
+	"interfaces":     joinFields(func(f *Field) string { return f.Name + " " + variadicPrefix(f.Type) + "interface{}" }),
+	"pointers":       joinFields(func(f *Field) string { return f.Name + " *" + f.Type }),
+	"derefs":         joinFields(func(f *Field) string { return "*" + f.Name }),
+	"spread":         spread,
+	"fixed":          fixed,
+	"variadic":       variadic,
+	"variadicPrefix": variadicPrefix,
+	"slice":          slice,
+	"elem":           elem,
+	"results":        results,
+	"rename":         rename,
+	"exported":       exported,
+	"title":          helpers.ToTitle,
+	"camel":          helpers.ToCamel,
+}
+
+// Create a helper which formats each field and joins them with ", "
+func joinFields(format func(f *Field) string) func(fields []*Field) string {
+	return func(fields []*Field) string {
+		formatted := []string{}
+		for _, f := range fields {
+			formatted = append(formatted, format(f))
+		}
+		return strings.Join(formatted, ", ")
+	}
+}
+
+/*
+Format the types of the fields as the results of a function. Multiple results
+are wrapped in parenthesis.
+*/
+func results(fields []*Field) string {
+	resultTypes := joinFields(func(f *Field) string { return f.Type })(fields)
+	if len(fields) > 1 {
+		resultTypes = "(" + resultTypes + ")"
+	}
+	return resultTypes
+}
+
+/*
+Join the names of the fields as the arguments of a call. A variadic field is
+spread into the call with "...".
+*/
+func spread(fields []*Field) string {
+	call := joinFields(func(f *Field) string { return f.Name })(fields)
+	if variadic(fields) != nil {
+		call += "..."
+	}
+	return call
+}
+
+// All of the fields except for a trailing variadic one
+func fixed(fields []*Field) []*Field {
+	if variadic(fields) != nil {
+		return fields[:len(fields)-1]
+	}
+	return fields
+}
+
+// The trailing variadic field, nil if there is none
+func variadic(fields []*Field) *Field {
+	if len(fields) > 0 && strings.HasPrefix(fields[len(fields)-1].Type, "...") {
+		return fields[len(fields)-1]
+	}
+	return nil
+}
+
+// "..." for a variadic type, so a replacement type can stay variadic
+func variadicPrefix(typeString string) string {
+	if strings.HasPrefix(typeString, "...") {
+		return "..."
+	}
+	return ""
+}
+
+// The type of the values of a variadic type, "T" for "...T"
+func elem(typeString string) string {
+	return strings.TrimPrefix(typeString, "...")
+}
+
+// Convert a variadic type like "...T" into the slice type "[]T" it holds
+func slice(typeString string) string {
+	if variadic, ok := strings.CutPrefix(typeString, "..."); ok {
+		return "[]" + variadic
+	}
+	return typeString
+}
+
+// Copy the fields with their names replaced by $prefix0...$prefixN
+func rename(prefix string, fields []*Field) []*Field {
+	renamed := []*Field{}
+	for i, f := range fields {
+		copied := *f
+		copied.Name = prefix + strconv.Itoa(i)
+		renamed = append(renamed, &copied)
+	}
+	return renamed
+}
+
+/*
+Copy the fields with exported names, for use as the fields of a struct. Values
+without a name, which are named _a0..._aN, are named after their type instead,
+such as "User" for "*repo.User" and "Err" for "error". Values named "_" and
+values whose type has no simple name, such as a map or a func, are named
+$prefix0...$prefixN instead.
+*/
+func exported(prefix string, fields []*Field) []*Field {
+	exportedFields := []*Field{}
+	used := map[string]bool{}
+	for i, f := range fields {
+		name := f.Name
+		if strings.HasPrefix(name, "_a") {
+			name = typeName(f.Type)
+		}
+		name = helpers.ToTitle(name)
+		if name == "" || name == "_" {
+			name = prefix + strconv.Itoa(i)
+		}
+		for used[name] {
+			name += strconv.Itoa(i)
+		}
+		used[name] = true
+
+		copied := *f
+		copied.Name = name
+		exportedFields = append(exportedFields, &copied)
+	}
+	return exportedFields
+}
+
+// The name of a type without its package, or "" if it has none
+func typeName(typeString string) string {
+	typeString = strings.TrimLeft(typeString, "*.[]")
+	typeString, _, _ = strings.Cut(typeString, "[")
+	if index := strings.LastIndex(typeString, "."); index != -1 {
+		typeString = typeString[index+1:]
+	}
+
+	if typeString == "error" {
+		return "err"
+	}
+	if typeString == "map" {
+		return ""
+	}
+	for _, r := range typeString {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return ""
+		}
+	}
+	return typeString
+}
@@ -0,0 +1,64 @@
+package templates
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExported(t *testing.T) {
+	cases := []struct {
+		name     string
+		fields   []*Field
+		expected []string
+	}{
+		{
+			name:     "named values are titled",
+			fields:   []*Field{{Name: "ctx", Type: "context.Context"}, {Name: "id", Type: "int"}},
+			expected: []string{"Ctx", "Id"},
+		},
+		{
+			name:     "unnamed values are named after their type",
+			fields:   []*Field{{Name: "_a0", Type: "*repo.User"}, {Name: "_a1", Type: "error"}},
+			expected: []string{"User", "Err"},
+		},
+		{
+			name:     "duplicate type names get the index",
+			fields:   []*Field{{Name: "_a0", Type: "int"}, {Name: "_a1", Type: "int"}},
+			expected: []string{"Int", "Int1"},
+		},
+		{
+			name: "types without a simple name use the prefix",
+			fields: []*Field{
+				{Name: "_a0", Type: "map[string]int"},
+				{Name: "_a1", Type: "func(int) error"},
+				{Name: "_a2", Type: "chan int"},
+				{Name: "_a3", Type: "interface{}"},
+				{Name: "_a4", Type: "struct{}"},
+			},
+			expected: []string{"Ret0", "Ret1", "Ret2", "Ret3", "Ret4"},
+		},
+		{
+			name:     "blank names use the prefix",
+			fields:   []*Field{{Name: "_", Type: "int"}, {Name: "name", Type: "string"}, {Name: "_", Type: "int"}},
+			expected: []string{"Ret0", "Name", "Ret2"},
+		},
+		{
+			name:     "fallbacks don't collide with real names",
+			fields:   []*Field{{Name: "ret1", Type: "int"}, {Name: "_", Type: "int"}},
+			expected: []string{"Ret1", "Ret11"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			names := []string{}
+			for _, f := range exported("Ret", c.fields) {
+				assert.True(t, token.IsIdentifier(f.Name) && f.Name != "_", "%q is not a usable field name", f.Name)
+				names = append(names, f.Name)
+			}
+			assert.Equal(t, c.expected, names)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package templates
+
+const (
+
+	// Executed with the componentwire.Plan.
+	Wire = `
+type App struct {
+{{- range .Steps}}
+	{{.Field}} {{.Type}}
+{{- end}}
+
+	// Starts and stops the components in dependency order
+	Lifecycle *lifecycle.Manager
+}
+
+type AppConfig struct {
+{{- range .Config}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+
+func BuildApp(appConfig AppConfig) *App {
+	app := &App{
+		Lifecycle: lifecycle.NewManager(),
+	}
+{{range .Steps}}{{template "wireStep" .}}{{end}}
+	return app
+}
+`
+
+	/*
+		Executed with each componentwire.Step. Components with Start or Stop
+		methods are registered with the lifecycle manager.
+	*/
+	WireStep = `
+	app.{{.Field}} = {{.New}}({{.Params}}{
+{{- range .Args}}
+{{- if .Provider}}
+		{{.Field}}: app.{{.Provider.Field}},
+{{- else if .Config}}
+		{{.Field}}: appConfig.{{.Config.Name}},
+{{- end}}
+{{- end}}
+	})
+{{- if .IsLifecycle}}
+	app.Lifecycle.Register({{printf "%q" .Field}}, app.{{.Field}}{{range .LifecycleDeps}}, {{printf "%q" .Field}}{{end}})
+{{- end}}
+`
+)
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"example.com/fixture/store"
+)
+
+type service struct {
+	/*
+		generate::components
+		scaffoldTests::true
+		scaffoldBenchmarks::true
+	*/
+	store store.Store      `pkg:"-"`
+	now   func() time.Time `mock:"func"`
+}
+
+type Params struct {
+	Store store.Store
+	Now   func() time.Time
+}
+
+func (p *Params) Convert() *service {
+	return &service{
+		store: p.Store,
+		now:   p.Now,
+	}
+}
+
+// An unnamed map return and a blank param
+func (s *service) Counts(ctx context.Context, _ string) (map[string]int, error) {
+	count, err := s.store.Get(ctx, "count")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"count": count}, nil
+}
+
+// A variadic param
+func (s *service) Tag(ctx context.Context, ids ...int) error {
+	for _, id := range ids {
+		err := s.store.Put(ctx, strconv.Itoa(id), 1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A func field and no args
+func (s *service) Stamp() time.Time {
+	return s.now()
+}
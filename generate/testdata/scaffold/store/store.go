@@ -0,0 +1,30 @@
+package store
+
+import "context"
+
+type store struct {
+	/*
+		generate::components
+		skipTestFile::true
+	*/
+	values map[string]int
+}
+
+type Params struct {
+	Values map[string]int
+}
+
+func (p *Params) Convert() *store {
+	return &store{
+		values: p.Values,
+	}
+}
+
+func (s *store) Get(ctx context.Context, key string) (int, error) {
+	return s.values[key], nil
+}
+
+func (s *store) Put(ctx context.Context, key string, value int) error {
+	s.values[key] = value
+	return nil
+}
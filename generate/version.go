@@ -0,0 +1,24 @@
+package generate
+
+import (
+	"fmt"
+
+	"github.com/flywingedai/components/generate/helpers"
+	"github.com/spf13/cobra"
+)
+
+func newVersionCmd() *cobra.Command {
+
+	versionCommand := &cobra.Command{}
+
+	versionCommand.Use = "version"
+	versionCommand.Short = "Print the version of the generator recorded in generated code"
+	versionCommand.Example = "components version"
+	versionCommand.Args = cobra.NoArgs
+
+	versionCommand.Run = func(cmd *cobra.Command, args []string) {
+		fmt.Println("components " + helpers.CurrentVersion())
+	}
+
+	return versionCommand
+}
@@ -0,0 +1,31 @@
+package generate
+
+import (
+	"github.com/flywingedai/components/generate/componentparser"
+	"github.com/flywingedai/components/generate/componentwire"
+	"github.com/flywingedai/components/generate/helpers"
+	"github.com/spf13/cobra"
+)
+
+func newWireCmd() *cobra.Command {
+
+	wireCommand := &cobra.Command{}
+
+	wireCommand.Use = "wire $DIRECTORY"
+	wireCommand.Short = "Generate an App struct and BuildApp function that build all the components in dependency order"
+	wireCommand.Example = "components wire ./ --config .components.yml"
+	wireCommand.Args = cobra.ExactArgs(1)
+
+	wireCommand.Run = func(cmd *cobra.Command, args []string) {
+		componentsConfig := loadConfig(cmd)
+
+		p := componentparser.New()
+		p.Args.Directory = args[0]
+		p.Parse()
+
+		plan := componentwire.New(p.Structs, componentsConfig.Wire)
+		generateWire(helpers.NewSession(), plan, componentsConfig.Wire.Output, componentsConfig.Templates)
+	}
+
+	return wireCommand
+}
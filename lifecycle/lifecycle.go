@@ -0,0 +1,221 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Components which own goroutines or connections that must be started
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Components which own goroutines or connections that must be stopped
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+var DefaultStartTimeout = 15 * time.Second
+var DefaultStopTimeout = 15 * time.Second
+
+/*
+The Manager starts registered components in dependency order and stops them in
+reverse. Components only need to implement the methods they care about, so a
+component which is only a Stopper is simply skipped during Start.
+*/
+type Manager struct {
+
+	// Time allowed for each individual Start and Stop call
+	StartTimeout time.Duration
+	StopTimeout  time.Duration
+
+	mutex   sync.Mutex
+	entries []*entry
+	started []*entry
+}
+
+// A component registered with the manager
+type entry struct {
+	name      string
+	component interface{}
+	dependsOn []string
+}
+
+// Create a new manager with the default timeouts
+func NewManager() *Manager {
+	return &Manager{
+		StartTimeout: DefaultStartTimeout,
+		StopTimeout:  DefaultStopTimeout,
+	}
+}
+
+/*
+Register a component with the manager. The component is started after all the
+components it depends on and stopped before them. Panics if the name has
+already been registered.
+*/
+func (m *Manager) Register(name string, component interface{}, dependsOn ...string) *Manager {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, e := range m.entries {
+		if e.name == name {
+			panic("component " + name + " registered with the lifecycle manager more than once")
+		}
+	}
+
+	m.entries = append(m.entries, &entry{
+		name:      name,
+		component: component,
+		dependsOn: dependsOn,
+	})
+	return m
+}
+
+/*
+Start every registered component in dependency order. If any component fails to
+start, everything that was already started is stopped again in reverse order
+and all the errors are returned together.
+*/
+func (m *Manager) Start(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.started) > 0 {
+		return errors.New("lifecycle manager has already been started")
+	}
+
+	ordered, err := m.order()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range ordered {
+		m.started = append(m.started, e)
+
+		starter, ok := e.component.(Starter)
+		if !ok {
+			continue
+		}
+
+		err := call(ctx, m.StartTimeout, starter.Start)
+		if err != nil {
+			startErr := fmt.Errorf("start %s: %w", e.name, err)
+
+			// The failed component is not stopped as it never started
+			m.started = m.started[:len(m.started)-1]
+			return errors.Join(startErr, m.stop(ctx))
+		}
+	}
+
+	return nil
+}
+
+/*
+Stop every started component in the reverse of the order they were started in.
+Every component is stopped even if some fail, and all the errors are returned
+together.
+*/
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.stop(ctx)
+}
+
+// Internal stop which requires the lock to already be held
+func (m *Manager) stop(ctx context.Context) error {
+	errs := []error{}
+	for i := len(m.started) - 1; i >= 0; i-- {
+		e := m.started[i]
+
+		stopper, ok := e.component.(Stopper)
+		if !ok {
+			continue
+		}
+
+		err := call(ctx, m.StopTimeout, stopper.Stop)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", e.name, err))
+		}
+	}
+
+	m.started = nil
+	return errors.Join(errs...)
+}
+
+/*
+Order the entries so every component comes after its dependencies. Ties keep the
+registration order.
+*/
+func (m *Manager) order() ([]*entry, error) {
+	names := map[string]*entry{}
+	for _, e := range m.entries {
+		names[e.name] = e
+	}
+
+	for _, e := range m.entries {
+		for _, dependency := range e.dependsOn {
+			if _, ok := names[dependency]; !ok {
+				return nil, fmt.Errorf("component %s depends on %s which is not registered", e.name, dependency)
+			}
+		}
+	}
+
+	done := map[string]bool{}
+	ordered := []*entry{}
+	for len(ordered) < len(m.entries) {
+		progress := false
+		for _, e := range m.entries {
+			if done[e.name] {
+				continue
+			}
+
+			ready := true
+			for _, dependency := range e.dependsOn {
+				if !done[dependency] {
+					ready = false
+				}
+			}
+
+			if ready {
+				done[e.name] = true
+				ordered = append(ordered, e)
+				progress = true
+			}
+		}
+
+		if !progress {
+			return nil, errors.New("dependency cycle between lifecycle components")
+		}
+	}
+
+	return ordered, nil
+}
+
+/*
+Call a Start or Stop method with a timeout. The method gets a context which is
+cancelled at the timeout, but a method ignoring its context is also abandoned at
+that point so a single component can't block the whole manager.
+*/
+func call(ctx context.Context, timeout time.Duration, method func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- method(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,209 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The Start and Stop calls of components in the order they were made
+type events struct {
+	mutex sync.Mutex
+	calls []string
+}
+
+func (e *events) record(call string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.calls = append(e.calls, call)
+}
+
+func (e *events) all() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([]string{}, e.calls...)
+}
+
+// A component recording its Start and Stop calls in a shared log
+type component struct {
+	name     string
+	log      *events
+	startErr error
+	stopErr  error
+
+	// Ignore the context and wait for the channel to be closed instead
+	blockStart chan struct{}
+	blockStop  chan struct{}
+}
+
+func (c *component) Start(ctx context.Context) error {
+	c.log.record("start " + c.name)
+	if c.blockStart != nil {
+		<-c.blockStart
+	}
+	return c.startErr
+}
+
+func (c *component) Stop(ctx context.Context) error {
+	c.log.record("stop " + c.name)
+	if c.blockStop != nil {
+		<-c.blockStop
+	}
+	return c.stopErr
+}
+
+// A component which only needs to be stopped
+type stopper struct {
+	component *component
+}
+
+func (s stopper) Stop(ctx context.Context) error {
+	return s.component.Stop(ctx)
+}
+
+// A Stopper implemented by a function
+type stopperFunc func(ctx context.Context) error
+
+func (f stopperFunc) Stop(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Create components sharing a single log
+func newComponents(names ...string) (map[string]*component, *events) {
+	log := &events{}
+	components := map[string]*component{}
+	for _, name := range names {
+		components[name] = &component{name: name, log: log}
+	}
+	return components, log
+}
+
+func TestManagerStopsInReverseOrder(t *testing.T) {
+	c, log := newComponents("db", "cache", "api", "worker")
+
+	m := NewManager().
+		Register("api", c["api"], "db", "cache").
+		Register("db", c["db"]).
+		Register("worker", stopper{c["worker"]}, "api").
+		Register("cache", c["cache"], "db")
+
+	assert.NoError(t, m.Start(context.Background()))
+	assert.NoError(t, m.Stop(context.Background()))
+
+	assert.Equal(t, []string{
+		"start db", "start cache", "start api",
+		"stop worker", "stop api", "stop cache", "stop db",
+	}, log.all())
+}
+
+func TestManagerStopsStartedComponentsWhenStartFails(t *testing.T) {
+	c, log := newComponents("db", "cache", "api")
+	failed := errors.New("failed")
+	c["cache"].startErr = failed
+
+	m := NewManager().
+		Register("db", c["db"]).
+		Register("cache", c["cache"], "db").
+		Register("api", c["api"], "cache")
+
+	err := m.Start(context.Background())
+	assert.ErrorIs(t, err, failed)
+	assert.EqualError(t, err, "start cache: failed")
+
+	// The failed component never started, so only the db is stopped again
+	assert.Equal(t, []string{"start db", "start cache", "stop db"}, log.all())
+}
+
+func TestManagerJoinsStopErrors(t *testing.T) {
+	c, log := newComponents("db", "cache", "api")
+	dbErr, apiErr := errors.New("db failed"), errors.New("api failed")
+	c["db"].stopErr = dbErr
+	c["api"].stopErr = apiErr
+
+	m := NewManager().
+		Register("db", c["db"]).
+		Register("cache", c["cache"], "db").
+		Register("api", c["api"], "cache")
+
+	assert.NoError(t, m.Start(context.Background()))
+	err := m.Stop(context.Background())
+
+	// Every component is stopped even though some of them failed
+	assert.Equal(t, []string{
+		"start db", "start cache", "start api",
+		"stop api", "stop cache", "stop db",
+	}, log.all())
+	assert.ErrorIs(t, err, dbErr)
+	assert.ErrorIs(t, err, apiErr)
+	assert.EqualError(t, err, "stop api: api failed\nstop db: db failed")
+}
+
+func TestManagerJoinsStartAndStopErrors(t *testing.T) {
+	c, _ := newComponents("db", "api")
+	startErr, stopErr := errors.New("start failed"), errors.New("stop failed")
+	c["db"].stopErr = stopErr
+	c["api"].startErr = startErr
+
+	m := NewManager().
+		Register("db", c["db"]).
+		Register("api", c["api"], "db")
+
+	err := m.Start(context.Background())
+	assert.ErrorIs(t, err, startErr)
+	assert.ErrorIs(t, err, stopErr)
+	assert.EqualError(t, err, "start api: start failed\nstop db: stop failed")
+}
+
+func TestManagerTimeouts(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	c, log := newComponents("db", "api")
+	c["api"].blockStart = block
+	c["db"].blockStop = block
+
+	m := NewManager().
+		Register("db", c["db"]).
+		Register("api", c["api"], "db")
+	m.StartTimeout = 10 * time.Millisecond
+	m.StopTimeout = 10 * time.Millisecond
+
+	// A component ignoring its context is abandoned at the timeout
+	err := m.Start(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, "start api: context deadline exceeded\nstop db: context deadline exceeded")
+	assert.Equal(t, []string{"start db", "start api", "stop db"}, log.all())
+}
+
+func TestManagerTimeoutsUseTheirContext(t *testing.T) {
+	m := NewManager()
+	m.StopTimeout = 10 * time.Millisecond
+	m.Register("db", stopperFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("gave up")
+	}))
+
+	assert.NoError(t, m.Start(context.Background()))
+	start := time.Now()
+	err := m.Stop(context.Background())
+	assert.Less(t, time.Since(start), DefaultStopTimeout)
+	assert.Error(t, err)
+}
+
+func TestManagerOrderErrors(t *testing.T) {
+	c, _ := newComponents("db", "api")
+
+	m := NewManager().Register("api", c["api"], "db")
+	assert.EqualError(t, m.Start(context.Background()), "component api depends on db which is not registered")
+
+	m = NewManager().
+		Register("db", c["db"], "api").
+		Register("api", c["api"], "db")
+	assert.EqualError(t, m.Start(context.Background()), "dependency cycle between lifecycle components")
+
+	assert.Panics(t, func() { NewManager().Register("db", c["db"]).Register("db", c["db"]) })
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"reflect"
+
+	"github.com/stretchr/testify/mock"
+)
+
+/*
+The most values a variadic argument can have for a call to still be allowed by
+the generated AllowAll() chains. Expectations match an exact number of
+arguments, so one is registered for each number of values up to this.
+*/
+var MaxAllowedVariadic = 8
+
+// mock.Anything for each of the arguments
+func Anythings(count int) []interface{} {
+	args := []interface{}{}
+	for i := 0; i < count; i++ {
+		args = append(args, mock.Anything)
+	}
+	return args
+}
+
+/*
+Run by every call marked with Fallback. The calls are recognized by it, so
+nothing outside of the calls themselves has to keep track of them.
+*/
+func fallbackRun(mock.Arguments) {}
+
+/*
+Mark a call as a fallback, which is only used when no other expectation for
+the method matches. Used by the generated AllowAll() chains.
+*/
+func Fallback(call *mock.Call) {
+	call.Run(fallbackRun)
+}
+
+// Whether or not the call was marked with Fallback
+func isFallback(call *mock.Call) bool {
+	return call.RunFn != nil && reflect.ValueOf(call.RunFn).Pointer() == reflect.ValueOf(fallbackRun).Pointer()
+}
+
+/*
+Move the fallbacks for the method of the call behind every other expectation
+of its mock. Testify uses the first expectation which matches a call, so this
+gives the call precedence over any AllowAll() registered before it. Used by the
+generated chains.
+
+The expectations are reordered without the lock testify keeps private, so this
+must only be called while nothing can call the mock. That is the case for the
+chains passed to TestOptions.Mock(), which are set up before the test runs.
+*/
+func Prefer(call *mock.Call) {
+	parent := call.Parent
+	preferred, fallback := []*mock.Call{}, []*mock.Call{}
+	for _, expected := range parent.ExpectedCalls {
+		if expected.Method == call.Method && isFallback(expected) {
+			fallback = append(fallback, expected)
+		} else {
+			preferred = append(preferred, expected)
+		}
+	}
+	parent.ExpectedCalls = append(preferred, fallback...)
+}
@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPreferFallback(t *testing.T) {
+	m := &mock.Mock{}
+	m.Test(t)
+
+	Fallback(m.On("Get", mock.Anything).Return(0).Maybe())
+	Prefer(m.On("Get", 2).Return(2))
+
+	assert.Equal(t, 2, m.MethodCalled("Get", 2).Int(0))
+	assert.Equal(t, 0, m.MethodCalled("Get", 3).Int(0))
+	assert.Equal(t, 2, m.ExpectedCalls[0].Arguments[0])
+	assert.True(t, isFallback(m.ExpectedCalls[1]))
+	assert.False(t, isFallback(m.ExpectedCalls[0]))
+}
@@ -0,0 +1,40 @@
+package tests
+
+import "testing"
+
+/*
+Run the call b.N times, timing only the calls. Everything done before Bench,
+such as building the mocks and setting up their expectations, is excluded from
+the results. Used by the generated Benchmark$Component_$Method functions.
+
+Testify mocks record every call made against them in mock.Calls, and find the
+matching expectation on each call. The recording is part of the timed calls, so
+the time and allocations of each call include it, and the memory it holds grows
+with b.N. The results are fine for comparing one version of a method against
+another, but a benchmark which needs the cost of the method alone should pass
+hand-written fakes in the params instead of the mocks.
+*/
+func Bench(b *testing.B, call func()) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		call()
+	}
+	b.StopTimer()
+}
+
+/*
+Bench with a setup run before each of the calls. The timer is stopped while
+the setup runs, so only the calls are timed.
+*/
+func BenchEach(b *testing.B, setup func(), call func()) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		setup()
+		b.StartTimer()
+		call()
+	}
+	b.StopTimer()
+}
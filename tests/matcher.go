@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+/*
+A typed predicate on a single argument of a mocked call, used by the generated
+$Method_M() chains. Any func(T) bool literal can be passed where a Matcher is
+expected, as well as the matchers below. A nil Matcher matches anything.
+*/
+type Matcher[T any] func(T) bool
+
+/*
+Convert a Matcher into an argument testify can match calls against. A value
+which doesn't match is reported by testify itself, as a "not matched by" line
+in the diff of the closest call holding the position of the argument and the
+value it was called with.
+*/
+func MatchArg[T any](matcher Matcher[T]) interface{} {
+	if matcher == nil {
+		return mock.Anything
+	}
+	return mock.MatchedBy(func(value T) bool {
+		return matcher(value)
+	})
+}
+
+// MatchArg for each of the values of a variadic argument
+func MatchArgs[T any](matchers []Matcher[T]) []interface{} {
+	args := []interface{}{}
+	for _, matcher := range matchers {
+		args = append(args, MatchArg(matcher))
+	}
+	return args
+}
+
+//////////////
+// MATCHERS //
+//////////////
+
+// Match any value of the type
+func Any[T any]() Matcher[T] {
+	return func(T) bool { return true }
+}
+
+// Match values equal to the expected value, the same way testify compares them
+func Eq[T any](expected T) Matcher[T] {
+	return func(value T) bool {
+		return assert.ObjectsAreEqual(expected, value)
+	}
+}
+
+// Match values which don't match the matcher
+func Not[T any](matcher Matcher[T]) Matcher[T] {
+	return func(value T) bool {
+		return !matcher(value)
+	}
+}
+
+// Match values which match every one of the matchers
+func All[T any](matchers ...Matcher[T]) Matcher[T] {
+	return func(value T) bool {
+		for _, matcher := range matchers {
+			if !matcher(value) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Match values which match at least one of the matchers
+func OneOf[T any](matchers ...Matcher[T]) Matcher[T] {
+	return func(value T) bool {
+		for _, matcher := range matchers {
+			if matcher(value) {
+				return true
+			}
+		}
+		return false
+	}
+}
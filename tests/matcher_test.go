@@ -0,0 +1,21 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMatchArgDiff(t *testing.T) {
+	expected := mock.Arguments{MatchArg(Eq(1)), MatchArg[string](nil)}
+	expected = append(expected, MatchArgs([]Matcher[int]{Not(Eq(0))})...)
+
+	diff, failures := expected.Diff([]interface{}{1, "a", 2})
+	assert.Equal(t, 0, failures, diff)
+
+	diff, failures = expected.Diff([]interface{}{2, "a", 0})
+	assert.Equal(t, 2, failures)
+	assert.Contains(t, diff, "0: FAIL:  (int=2) not matched by func(int) bool")
+	assert.Contains(t, diff, "2: FAIL:  (int=0) not matched by func(int) bool")
+}
@@ -0,0 +1,21 @@
+package tests
+
+/*
+A typed handle to a method of a component C. The test file of every component
+has a method_$Method() function returning the handle of each of its methods,
+which can be used in place of the method name in the method tests.
+*/
+type Method[C any] struct {
+	Name string
+
+	// Fetch the method value from the component
+	get func(component C) interface{}
+}
+
+/*
+Create a handle to the method of a component. The function returns the method
+value, as in "return c.Get", so the method is checked by the compiler.
+*/
+func NewMethod[C any](name string, get func(component C) interface{}) Method[C] {
+	return Method[C]{Name: name, get: get}
+}
@@ -178,6 +178,21 @@ func (to *TestOptions[C, M, D]) CreateMethodTest(method, testName string) *TestC
 	}
 }
 
+/*
+Create a new test of the method of the component behind the handle. Handles are
+generated as method_$Method() in the test file of the component, so unlike the
+names given to CreateMethodTest() they are checked by the compiler.
+*/
+func (to *TestOptions[C, M, D]) CreateMethodHandleTest(method Method[C], testName string) *TestConfig[C, M, D] {
+	return &TestConfig[C, M, D]{
+		name: testName,
+		getTestFunction: func(state *TestState[C, M, D]) reflect.Value {
+			return reflect.ValueOf(method.get(state.Component))
+		},
+		Options: to,
+	}
+}
+
 /*
 Create a new test which automatically fetches the function given
 at runtime.
@@ -210,6 +225,14 @@ func (to *TestOptions[C, M, D]) RegisterMethodTest(method, testName string) *Tes
 	return to.CreateMethodTest(method, testName).Register(to.tester)
 }
 
+/*
+Create and register a new test of the method of the component behind the
+handle.
+*/
+func (to *TestOptions[C, M, D]) RegisterMethodHandleTest(method Method[C], testName string) *TestConfig[C, M, D] {
+	return to.CreateMethodHandleTest(method, testName).Register(to.tester)
+}
+
 /*
 Create and register a new test which automatically fetches the function given
 at runtime.
@@ -2,10 +2,21 @@ package tests
 
 import (
 	"reflect"
+
+	"github.com/stretchr/testify/mock"
 )
 
 var DefaultMockPriority = -20
 
+/*
+Implemented by every chain generated for the mocks of a component. Evaluating
+the chain against the mocks sets up its expectation and returns the underlying
+testify call, which is how NotBefore() orders one chain after another.
+*/
+type MockChain[M any] interface {
+	MockCall(m *M) *mock.Call
+}
+
 /*
 Function to automatically handle a mock chain. Use the autogenerated functions
 from the components command placed at the bottom of your component definition
@@ -0,0 +1,64 @@
+package tests
+
+/*
+Assert that the named method was called exactly "times" times on the spy
+fetched from the mocks.
+
+ex. SpyCalled(spy_repo(), "Get", 1)
+
+The spy_$Field() functions are generated for the fields tagged mock:"spy".
+
+Has Priority = tests.DefaultOutputPriority
+*/
+func (to *TestOptions[C, M, D]) SpyCalled(
+	fetch func(m *M) SpyLog,
+	method string,
+	times int,
+) *TestOptions[C, M, D] {
+	return to.copyAndAppend(DefaultOutputPriority, func(state *TestState[C, M, D]) {
+		calls := fetch(state.Mocks).CallsTo(method)
+		state.Assertions.Len(calls, times, "unexpected number of calls to "+method)
+	})
+}
+
+/*
+Assert that the most recent call to the named method on the spy was made with
+the given args. Use tests.Ignore to skip checking a particular arg.
+Has Priority = tests.DefaultOutputPriority
+Supports DeRef()
+*/
+func (to *TestOptions[C, M, D]) SpyCalledWith(
+	fetch func(m *M) SpyLog,
+	method string,
+	args ...interface{},
+) *TestOptions[C, M, D] {
+	return to.copyAndAppend(DefaultOutputPriority, func(state *TestState[C, M, D]) {
+		call := fetch(state.Mocks).LastCallTo(method)
+		if call == nil {
+			state.Assertions.Fail("no calls to " + method + " were recorded by the spy")
+			return
+		}
+
+		if !state.Assertions.Len(call.Args, len(args), "unexpected number of args for "+method) {
+			return
+		}
+		for i, arg := range args {
+			assertInterfaceEqual(state.Assertions, handleDereference(arg), call.Args[i])
+		}
+	})
+}
+
+/*
+Check something arbitrary about the calls recorded by a spy. If the provided
+callback returns an error, the test will fail.
+Has Priority = tests.DefaultOutputPriority
+*/
+func (to *TestOptions[C, M, D]) ValidateSpy(
+	fetch func(m *M) SpyLog,
+	f func(spy SpyLog) error,
+) *TestOptions[C, M, D] {
+	return to.copyAndAppend(DefaultOutputPriority, func(state *TestState[C, M, D]) {
+		err := f(fetch(state.Mocks))
+		state.Assertions.Nil(err)
+	})
+}
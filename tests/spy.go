@@ -0,0 +1,139 @@
+package tests
+
+import "sync"
+
+/*
+A single call that was recorded by a spy. The args and returns are stored in
+the same order they appear in the method signature.
+*/
+type SpyCall struct {
+	Method  string
+	Args    []interface{}
+	Returns []interface{}
+}
+
+/*
+Anything that exposes a recorded call log, such as the *Spy returned by the
+SpyLog() method of every generated {{InterfaceName}}Spy.
+*/
+type SpyLog interface {
+	Calls() []SpyCall
+	CallsTo(method string) []SpyCall
+	LastCall() *SpyCall
+	LastCallTo(method string) *SpyCall
+}
+
+/*
+The Spy is the goroutine-safe call log held by every generated
+{{InterfaceName}}Spy, and returned by its SpyLog() method. It records every call
+made against the spy and stores the return handlers that were configured for
+each method.
+*/
+type Spy struct {
+	mutex    sync.Mutex
+	calls    []SpyCall
+	handlers map[string]interface{}
+}
+
+//////////////
+// HANDLERS //
+//////////////
+
+/*
+Set the handler which computes the return values of a method. Used by the
+generated {{Method}}_Returns and {{Method}}_Func methods. The handler must have
+the exact signature of the method or it will be ignored.
+*/
+func (s *Spy) SetHandler(method string, handler interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.handlers == nil {
+		s.handlers = map[string]interface{}{}
+	}
+	s.handlers[method] = handler
+}
+
+// Fetch the handler for a method. Returns nil if none was configured.
+func (s *Spy) Handler(method string) interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.handlers[method]
+}
+
+///////////////
+// RECORDING //
+///////////////
+
+// Record a call made against the spy. Called by the generated spy methods.
+func (s *Spy) Record(method string, args []interface{}, returns []interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.calls = append(s.calls, SpyCall{
+		Method:  method,
+		Args:    args,
+		Returns: returns,
+	})
+}
+
+// Clear out all recorded calls. Configured handlers are left untouched.
+func (s *Spy) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls = nil
+}
+
+/////////////
+// QUERIES //
+/////////////
+
+// All the calls recorded by the spy in the order they were made.
+func (s *Spy) Calls() []SpyCall {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	calls := make([]SpyCall, len(s.calls))
+	copy(calls, s.calls)
+	return calls
+}
+
+// All the calls to the named method in the order they were made.
+func (s *Spy) CallsTo(method string) []SpyCall {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	calls := []SpyCall{}
+	for _, call := range s.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// The number of times the named method was called.
+func (s *Spy) CallCount(method string) int {
+	return len(s.CallsTo(method))
+}
+
+// The most recent call made against the spy. Returns nil if there were none.
+func (s *Spy) LastCall() *SpyCall {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.calls) == 0 {
+		return nil
+	}
+	call := s.calls[len(s.calls)-1]
+	return &call
+}
+
+// The most recent call to the named method. Returns nil if there were none.
+func (s *Spy) LastCallTo(method string) *SpyCall {
+	calls := s.CallsTo(method)
+	if len(calls) == 0 {
+		return nil
+	}
+	return &calls[len(calls)-1]
+}
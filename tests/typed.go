@@ -0,0 +1,42 @@
+package tests
+
+/*
+A test of a single method of a component C with typed args and returns. The
+test file of every component has a test_$Method() builder for each method,
+which takes the $MethodArgs and $MethodReturns structs generated beside it.
+
+	tester.NewOptions().
+		RegisterTypedTest(test_Get().Args(GetArgs{Id: 5}).Returns(GetReturns{User: user}), "get")
+*/
+type TypedTest[C any] struct {
+	Method  Method[C]
+	Inputs  []interface{}
+	Outputs []interface{} // nil unless the returns were set
+}
+
+// Implemented by the generated test_$Method() builders
+type TypedTestBuilder[C any] interface {
+	TypedTest() TypedTest[C]
+}
+
+/*
+Set the inputs and expected outputs of the test from a typed builder. Only the
+returns which were set on the builder are checked.
+*/
+func (to *TestOptions[C, M, D]) Typed(builder TypedTestBuilder[C]) *TestOptions[C, M, D] {
+	test := builder.TypedTest()
+
+	options := to.SetInputs(test.Inputs...)
+	if test.Outputs != nil {
+		options = options.Outputs(test.Outputs...)
+	}
+	return options
+}
+
+/*
+Create and register a new test of the method of a typed builder, with its
+inputs and expected outputs.
+*/
+func (to *TestOptions[C, M, D]) RegisterTypedTest(builder TypedTestBuilder[C], testName string) *TestConfig[C, M, D] {
+	return to.Typed(builder).RegisterMethodHandleTest(builder.TypedTest().Method, testName)
+}
@@ -57,6 +57,30 @@ func RemoveInterfacePointer[T any](pointerInterface interface{}) interface{} {
 	return pointerInterface
 }
 
+/*
+RemoveInterfacePointer for each of the values of a variadic argument, which
+mockery records as separate arguments.
+*/
+func RemoveInterfacePointers[T any](pointerInterfaces []interface{}) []interface{} {
+	values := []interface{}{}
+	for _, pointerInterface := range pointerInterfaces {
+		values = append(values, RemoveInterfacePointer[T](pointerInterface))
+	}
+	return values
+}
+
+/*
+Convert the values of a variadic argument into the separate arguments mockery
+records them as, so they can be spread into a call expecting interface{}s.
+*/
+func VariadicArgs[T any](values []T) []interface{} {
+	args := []interface{}{}
+	for _, value := range values {
+		args = append(args, value)
+	}
+	return args
+}
+
 // Little helper for ensuring to output values are equal.
 func assertInterfaceEqual(parallelAssert *assert.Assertions, expected, actual interface{}) {
 